@@ -0,0 +1,480 @@
+// Package addon implements the server side of HipChat Connect add-ons.
+//
+// An Addon serves its descriptor, keeps track of the groups and rooms it is
+// installed in through a Store, and returns an authenticated HipChat client
+// for each installation:
+//
+//	a := addon.New(&addon.Descriptor{
+//		Key:  "com.example.deploy-bot",
+//		Name: "Deploy bot",
+//		Links: addon.Links{Self: "https://example.com/addon/capabilities"},
+//		Capabilities: addon.Capabilities{
+//			HipchatApiConsumer: &addon.ApiConsumer{Scopes: []string{hipchat.ScopeSendNotification}},
+//			Installable:        &addon.Installable{CallbackUrl: "https://example.com/addon/installable", AllowRoom: true},
+//		},
+//	}, addon.NewMemoryStore())
+//
+//	http.Handle("/addon/capabilities", a.DescriptorHandler())
+//	http.Handle("/addon/installable", a.InstallableHandler())
+//	http.Handle("/addon/installable/", a.InstallableHandler())
+//
+//	client, err := a.Client(ctx, oauthId)
+//
+// Installations are only accepted from the HipChat instances listed in
+// AllowedHosts, api.hipchat.com by default, and only once their credentials
+// generated a token. An installation can't be replaced by installing it again,
+// and is only deleted once HipChat revoked its credentials.
+//
+// The callbacks of the add-on are signed with the oauth secret of their
+// installation, which an Addon looks up for webhook.Verifier.
+package addon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/theodesp/go-hipchat/hipchat"
+	"github.com/theodesp/go-hipchat/webhook"
+	"golang.org/x/oauth2"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+)
+
+// DefaultAllowedHosts are the hosts installations are accepted from when
+// Addon.AllowedHosts is empty.
+var DefaultAllowedHosts = []string{"api.hipchat.com"}
+
+var invalidInstallation = errors.New("invalid_installation: oauthId, oauthSecret and capabilitiesUrl are required")
+var missingApiUrl = errors.New("missing_api_url: the capabilities of the instance have no API URL")
+var alreadyInstalled = errors.New("already_installed: an installation with this oauthId exists")
+var stillInstalled = errors.New("still_installed: HipChat still accepts the credentials of the installation")
+
+func untrustedHost(rawurl string) error {
+	return fmt.Errorf("untrusted_host: %v is not on a host in AllowedHosts", rawurl)
+}
+
+func invalidCredentials(err error) error {
+	return fmt.Errorf("invalid_credentials: the installation can't generate tokens: %v", err)
+}
+
+// Addon serves a HipChat Connect add-on.
+type Addon struct {
+	// The descriptor of the add-on.
+	Descriptor *Descriptor
+
+	// Where installations are persisted.
+	Store Store
+
+	// The HTTP client used to reach HipChat. http.DefaultClient is used when
+	// it's nil.
+	HTTPClient *http.Client
+
+	// The hosts of the HipChat instances the add-on can be installed in, for
+	// example hipchat.example.com. The capabilities and API URLs of
+	// installations must be on one of them. DefaultAllowedHosts is used when
+	// it's empty.
+	AllowedHosts []string
+
+	// OnInstalled, if set, is called once an installation is saved.
+	OnInstalled func(ctx context.Context, installation *Installation) error
+
+	// OnUninstalled, if set, is called before an installation is deleted.
+	OnUninstalled func(ctx context.Context, installation *Installation) error
+
+	mu         sync.Mutex
+	clients    map[string]*hipchat.Client
+	installing map[string]bool
+}
+
+// New returns an Addon serving the given descriptor and persisting its
+// installations in store.
+func New(descriptor *Descriptor, store Store) *Addon {
+	return &Addon{
+		Descriptor: descriptor,
+		Store:      store,
+		clients:    make(map[string]*hipchat.Client),
+		installing: make(map[string]bool),
+	}
+}
+
+// DescriptorHandler returns a handler serving the descriptor of the add-on.
+// Serve it at the URL of Descriptor.Links.Self.
+func (a *Addon) DescriptorHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(a.Descriptor)
+	})
+}
+
+// InstallableHandler returns a handler for the installed and uninstalled
+// callbacks. Serve it at the URL of the callbackUrl of the installable
+// capability, and at the paths below it.
+//
+// HipChat POSTs the installation to the callback URL when the add-on is
+// installed, and DELETEs the callback URL followed by the oauth id when it's
+// uninstalled. Installations coming from hosts outside AllowedHosts are
+// rejected with 403 Forbidden, and installations reusing the oauth id of a
+// saved one with 409 Conflict. Uninstalled callbacks for installations whose
+// credentials still generate tokens are rejected with 403 Forbidden.
+func (a *Addon) InstallableHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			a.installed(w, r)
+		case http.MethodDelete:
+			a.uninstalled(w, r)
+		default:
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func (a *Addon) installed(w http.ResponseWriter, r *http.Request) {
+	installation := new(Installation)
+	if err := json.NewDecoder(r.Body).Decode(installation); err != nil {
+		http.Error(w, fmt.Sprintf("invalid_installation: %v", err), http.StatusBadRequest)
+		return
+	}
+	if installation.OAuthId == "" || installation.OAuthSecret == "" || installation.CapabilitiesUrl == "" {
+		http.Error(w, invalidInstallation.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !a.allowedUrl(installation.CapabilitiesUrl) {
+		http.Error(w, untrustedHost(installation.CapabilitiesUrl).Error(), http.StatusForbidden)
+		return
+	}
+
+	ctx := r.Context()
+	if err := a.startInstall(ctx, installation.OAuthId); err != nil {
+		if err == alreadyInstalled {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer a.finishInstall(installation.OAuthId)
+
+	capabilities, err := a.getCapabilities(ctx, installation.CapabilitiesUrl)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	if capabilities.Capabilities != nil && capabilities.Capabilities.HipchatApiProvider != nil {
+		installation.ApiUrl = capabilities.Capabilities.HipchatApiProvider.Url
+	}
+	if installation.ApiUrl == "" && capabilities.Links != nil {
+		installation.ApiUrl = capabilities.Links.Api
+	}
+	if installation.ApiUrl == "" {
+		http.Error(w, missingApiUrl.Error(), http.StatusBadGateway)
+		return
+	}
+	if !a.allowedUrl(installation.ApiUrl) {
+		http.Error(w, untrustedHost(installation.ApiUrl).Error(), http.StatusForbidden)
+		return
+	}
+
+	// Anyone can POST to the callback, only save credentials HipChat issued
+	if err := a.checkCredentials(ctx, installation); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.Store.Save(ctx, installation); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	a.forgetClient(installation.OAuthId)
+
+	if a.OnInstalled != nil {
+		if err := a.OnInstalled(ctx, installation); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *Addon) uninstalled(w http.ResponseWriter, r *http.Request) {
+	oauthId := path.Base(r.URL.Path)
+	if oauthId == "" || oauthId == "/" || oauthId == "." {
+		http.Error(w, invalidInstallation.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	installation, err := a.Store.Get(ctx, oauthId)
+	if err == ErrNotInstalled {
+		// Already gone, HipChat may retry the callback
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Anyone knowing the oauth id can DELETE the callback, only delete
+	// installations HipChat revoked
+	if err := a.checkRevoked(ctx, installation); err != nil {
+		if err == stillInstalled {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	if a.OnUninstalled != nil {
+		if err := a.OnUninstalled(ctx, installation); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := a.Store.Delete(ctx, oauthId); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	a.forgetClient(oauthId)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// startInstall reserves the oauth id of an installation until finishInstall is
+// called, so concurrent callbacks can't replace each other.
+func (a *Addon) startInstall(ctx context.Context, oauthId string) error {
+	a.mu.Lock()
+	if a.installing[oauthId] {
+		a.mu.Unlock()
+		return alreadyInstalled
+	}
+	if a.installing == nil {
+		a.installing = make(map[string]bool)
+	}
+	a.installing[oauthId] = true
+	a.mu.Unlock()
+
+	_, err := a.Store.Get(ctx, oauthId)
+	if err == ErrNotInstalled {
+		return nil
+	}
+
+	a.finishInstall(oauthId)
+	if err == nil {
+		return alreadyInstalled
+	}
+
+	return err
+}
+
+func (a *Addon) finishInstall(oauthId string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.installing, oauthId)
+}
+
+// allowedUrl reports whether rawurl is an http(s) URL on one of the allowed
+// hosts.
+func (a *Addon) allowedUrl(rawurl string) bool {
+	u, err := url.Parse(rawurl)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return false
+	}
+
+	hosts := a.AllowedHosts
+	if len(hosts) == 0 {
+		hosts = DefaultAllowedHosts
+	}
+	for _, host := range hosts {
+		if host == u.Host || host == u.Hostname() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkCredentials generates a token with the credentials of an installation,
+// proving they were issued by the HipChat instance at its API URL.
+func (a *Addon) checkCredentials(ctx context.Context, installation *Installation) error {
+	client, err := hipchat.NewEnterpriseClient(installation.ApiUrl, a.HTTPClient)
+	if err != nil {
+		return invalidCredentials(err)
+	}
+
+	if _, _, err := client.OAuth.GenerateToken(ctx, a.tokenRequest(installation)); err != nil {
+		return invalidCredentials(err)
+	}
+
+	return nil
+}
+
+// checkRevoked generates a token with the credentials of an installation,
+// which HipChat refuses once the add-on is uninstalled. It returns
+// stillInstalled if a token is generated.
+func (a *Addon) checkRevoked(ctx context.Context, installation *Installation) error {
+	client, err := hipchat.NewEnterpriseClient(installation.ApiUrl, a.HTTPClient)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.OAuth.GenerateToken(ctx, a.tokenRequest(installation))
+	if err == nil {
+		return stillInstalled
+	}
+	if isInvalidClient(err) {
+		return nil
+	}
+
+	return err
+}
+
+// isInvalidClient reports whether a token request failed because the client
+// credentials were rejected.
+func isInvalidClient(err error) bool {
+	if hipchat.IsUnauthorized(err) {
+		return true
+	}
+
+	e, ok := err.(*hipchat.ErrorResponse)
+	return ok && e.Code == http.StatusBadRequest && strings.Contains(string(e.Body), "invalid_client")
+}
+
+// getCapabilities gets the capabilities descriptor of the HipChat instance an
+// installation comes from.
+func (a *Addon) getCapabilities(ctx context.Context, capabilitiesUrl string) (*hipchat.Capabilities, error) {
+	req, err := http.NewRequest(http.MethodGet, capabilitiesUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.httpClient().Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("capabilities_unavailable: GET %s: %d", capabilitiesUrl, resp.StatusCode)
+	}
+
+	capabilities := new(hipchat.Capabilities)
+	if err := json.NewDecoder(resp.Body).Decode(capabilities); err != nil {
+		return nil, err
+	}
+
+	return capabilities, nil
+}
+
+// Client returns a client of the HipChat API authenticated as the given
+// installation. Tokens are generated with the client_credentials grant and the
+// scopes of the descriptor, and are reused until they expire. The scopes are
+// also set on the client, so calls they don't allow fail before being sent.
+func (a *Addon) Client(ctx context.Context, oauthId string) (*hipchat.Client, error) {
+	a.mu.Lock()
+	client, ok := a.clients[oauthId]
+	a.mu.Unlock()
+	if ok {
+		return client, nil
+	}
+
+	installation, err := a.Store.Get(ctx, oauthId)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenClient, err := hipchat.NewEnterpriseClient(installation.ApiUrl, a.HTTPClient)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tokens outlive the request the client is created for
+	tokenCtx := context.Background()
+	if a.HTTPClient != nil {
+		tokenCtx = context.WithValue(tokenCtx, oauth2.HTTPClient, a.HTTPClient)
+	}
+
+	ts := tokenClient.OAuth.TokenSource(tokenCtx, a.tokenRequest(installation))
+
+	client, err = hipchat.NewEnterpriseClient(installation.ApiUrl, oauth2.NewClient(tokenCtx, ts))
+	if err != nil {
+		return nil, err
+	}
+	client.SetTokenScopes(a.scopes()...)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if cached, ok := a.clients[oauthId]; ok {
+		return cached, nil
+	}
+	if a.clients == nil {
+		a.clients = make(map[string]*hipchat.Client)
+	}
+	a.clients[oauthId] = client
+
+	return client, nil
+}
+
+// Secret returns the oauth secret of the given installation, which its
+// callbacks are signed with. It makes an Addon a webhook.SecretStore.
+func (a *Addon) Secret(ctx context.Context, oauthId string) (string, error) {
+	installation, err := a.Store.Get(ctx, oauthId)
+	if err != nil {
+		return "", err
+	}
+
+	return installation.OAuthSecret, nil
+}
+
+// Verifier returns a webhook.Verifier checking the callbacks of the add-on
+// against the secrets of its installations.
+func (a *Addon) Verifier() *webhook.Verifier {
+	return webhook.NewVerifier(a)
+}
+
+// tokenRequest returns the client_credentials grant of an installation, with
+// the scopes of the descriptor.
+func (a *Addon) tokenRequest(installation *Installation) *hipchat.TokenRequest {
+	return &hipchat.TokenRequest{
+		GrantType:    hipchat.GrantTypeClientCredentials,
+		Scopes:       a.scopes(),
+		ClientId:     installation.OAuthId,
+		ClientSecret: installation.OAuthSecret,
+	}
+}
+
+func (a *Addon) scopes() []string {
+	if a.Descriptor == nil || a.Descriptor.Capabilities.HipchatApiConsumer == nil {
+		return nil
+	}
+
+	return a.Descriptor.Capabilities.HipchatApiConsumer.Scopes
+}
+
+func (a *Addon) forgetClient(oauthId string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.clients, oauthId)
+}
+
+func (a *Addon) httpClient() *http.Client {
+	if a.HTTPClient != nil {
+		return a.HTTPClient
+	}
+
+	return http.DefaultClient
+}
@@ -0,0 +1,412 @@
+package addon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/theodesp/go-hipchat/hipchat"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type AddonTestSuite struct {
+	suite.Suite
+	addon       *Addon
+	store       *MemoryStore
+	api         *httptest.Server
+	addonServer *httptest.Server
+
+	tokens  int32
+	revoked int32
+}
+
+func (suite *AddonTestSuite) SetupTest() {
+	atomic.StoreInt32(&suite.tokens, 0)
+	atomic.StoreInt32(&suite.revoked, 0)
+
+	// HipChat instance the add-on is installed in
+	mux := http.NewServeMux()
+	suite.api = httptest.NewServer(mux)
+	mux.HandleFunc("/v2/capabilities", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"key":"hipchat","links":{"self":"%[1]s/v2/capabilities","api":"%[1]s/v2"},
+			"capabilities":{"hipchatApiProvider":{"url":"%[1]s/v2/"}}}`, suite.api.URL)
+	})
+	mux.HandleFunc("/v2/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.LoadInt32(&suite.revoked) {
+		case http.StatusBadRequest:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"invalid_client"}`)
+			return
+		case http.StatusInternalServerError:
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		id, secret, _ := r.BasicAuth()
+		if id != "oauth-id" || secret != "oauth-secret" || atomic.LoadInt32(&suite.revoked) != 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":{"code":401,"message":"Invalid OAuth session","type":"Unauthorized"}}`)
+			return
+		}
+		suite.Equal("client_credentials", r.FormValue("grant_type"))
+		suite.Equal("send_notification view_room", r.FormValue("scope"))
+		atomic.AddInt32(&suite.tokens, 1)
+		fmt.Fprint(w, `{"access_token":"token","token_type":"bearer","expires_in":3600,"scope":"send_notification view_room"}`)
+	})
+	mux.HandleFunc("/v2/foreign-capabilities", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"key":"hipchat","capabilities":{"hipchatApiProvider":{"url":"https://evil.example.com/v2/"}}}`)
+	})
+	mux.HandleFunc("/v2/room/1/notification", func(w http.ResponseWriter, r *http.Request) {
+		suite.Equal("Bearer token", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	suite.store = NewMemoryStore()
+	suite.addon = New(&Descriptor{
+		Key:         "com.example.addon",
+		Name:        "Example",
+		Description: "An example add-on",
+		Links:       Links{Self: "https://example.com/capabilities"},
+		Capabilities: Capabilities{
+			HipchatApiConsumer: &ApiConsumer{
+				Scopes:   []string{hipchat.ScopeSendNotification, hipchat.ScopeViewRoom},
+				FromName: "Example",
+			},
+			Installable: &Installable{CallbackUrl: "https://example.com/installable", AllowRoom: true},
+			Webhook: []*hipchat.Webhook{
+				{Url: "https://example.com/webhook", Event: hipchat.WebhookEventRoomMessage, Pattern: "^/example", Name: "Example"},
+			},
+		},
+	}, suite.store)
+	suite.addon.AllowedHosts = []string{"127.0.0.1"}
+
+	mux = http.NewServeMux()
+	mux.Handle("/capabilities", suite.addon.DescriptorHandler())
+	mux.Handle("/installable", suite.addon.InstallableHandler())
+	mux.Handle("/installable/", suite.addon.InstallableHandler())
+	suite.addonServer = httptest.NewServer(mux)
+}
+
+func (suite *AddonTestSuite) TearDownTest() {
+	suite.api.Close()
+	suite.addonServer.Close()
+}
+
+func (suite *AddonTestSuite) install() *http.Response {
+	return suite.installWith("oauth-id", "oauth-secret", suite.api.URL+"/v2/capabilities")
+}
+
+func (suite *AddonTestSuite) installWith(oauthId, oauthSecret, capabilitiesUrl string) *http.Response {
+	body := fmt.Sprintf(`{"oauthId":%q,"oauthSecret":%q,"capabilitiesUrl":%q,"groupId":1,"roomId":1}`, oauthId, oauthSecret, capabilitiesUrl)
+	resp, err := http.Post(suite.addonServer.URL+"/installable", "application/json", strings.NewReader(body))
+	suite.Nil(err)
+	resp.Body.Close()
+
+	return resp
+}
+
+// revoke makes token requests fail with the given status code, as HipChat
+// does once the add-on is uninstalled.
+func (suite *AddonTestSuite) revoke(code int) {
+	atomic.StoreInt32(&suite.revoked, int32(code))
+}
+
+func (suite *AddonTestSuite) uninstall() *http.Response {
+	req, _ := http.NewRequest(http.MethodDelete, suite.addonServer.URL+"/installable/oauth-id", nil)
+	resp, err := http.DefaultClient.Do(req)
+	suite.Nil(err)
+	resp.Body.Close()
+
+	return resp
+}
+
+func (suite *AddonTestSuite) TestDescriptor() {
+	resp, err := http.Get(suite.addonServer.URL + "/capabilities")
+	suite.Nil(err)
+	defer resp.Body.Close()
+
+	suite.Equal(http.StatusOK, resp.StatusCode)
+	suite.Equal("application/json", resp.Header.Get("Content-Type"))
+
+	var descriptor map[string]interface{}
+	suite.Nil(json.NewDecoder(resp.Body).Decode(&descriptor))
+	suite.Equal("com.example.addon", descriptor["key"])
+
+	capabilities := descriptor["capabilities"].(map[string]interface{})
+	suite.Equal(map[string]interface{}{
+		"scopes":   []interface{}{"send_notification", "view_room"},
+		"fromName": "Example",
+	}, capabilities["hipchatApiConsumer"])
+	suite.Equal(map[string]interface{}{
+		"callbackUrl": "https://example.com/installable",
+		"allowGlobal": false,
+		"allowRoom":   true,
+	}, capabilities["installable"])
+	suite.Equal(map[string]interface{}{
+		"url":     "https://example.com/webhook",
+		"event":   "room_message",
+		"pattern": "^/example",
+		"name":    "Example",
+	}, capabilities["webhook"].([]interface{})[0])
+
+	resp, err = http.Post(suite.addonServer.URL+"/capabilities", "application/json", nil)
+	suite.Nil(err)
+	resp.Body.Close()
+	suite.Equal(http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func (suite *AddonTestSuite) TestInstall() {
+	var installed *Installation
+	suite.addon.OnInstalled = func(ctx context.Context, installation *Installation) error {
+		installed = installation
+		return nil
+	}
+
+	resp := suite.install()
+	suite.Equal(http.StatusNoContent, resp.StatusCode)
+
+	installation, err := suite.store.Get(context.Background(), "oauth-id")
+	suite.Nil(err)
+	suite.Equal(&Installation{
+		OAuthId:         "oauth-id",
+		OAuthSecret:     "oauth-secret",
+		CapabilitiesUrl: suite.api.URL + "/v2/capabilities",
+		GroupId:         1,
+		RoomId:          1,
+		ApiUrl:          suite.api.URL + "/v2/",
+	}, installation)
+	suite.Equal(installation, installed)
+
+	// The credentials are checked before the installation is saved
+	suite.Equal(int32(1), atomic.LoadInt32(&suite.tokens))
+}
+
+func (suite *AddonTestSuite) TestInstall_hijack() {
+	suite.install()
+	installs := 0
+	suite.addon.OnInstalled = func(ctx context.Context, installation *Installation) error {
+		installs++
+		return nil
+	}
+
+	for _, secret := range []string{"stolen-secret", "oauth-secret"} {
+		resp := suite.installWith("oauth-id", secret, suite.api.URL+"/v2/capabilities")
+		suite.Equal(http.StatusConflict, resp.StatusCode, secret)
+	}
+
+	installation, err := suite.store.Get(context.Background(), "oauth-id")
+	suite.Nil(err)
+	suite.Equal("oauth-secret", installation.OAuthSecret)
+	suite.Equal(suite.api.URL+"/v2/", installation.ApiUrl)
+	suite.Equal(0, installs)
+	suite.Equal(int32(1), atomic.LoadInt32(&suite.tokens))
+}
+
+func (suite *AddonTestSuite) TestInstall_invalidCredentials() {
+	resp := suite.installWith("oauth-id", "forged-secret", suite.api.URL+"/v2/capabilities")
+	suite.Equal(http.StatusBadRequest, resp.StatusCode)
+
+	_, err := suite.store.Get(context.Background(), "oauth-id")
+	suite.Equal(ErrNotInstalled, err)
+}
+
+func (suite *AddonTestSuite) TestInstall_foreignHost() {
+	var fetched int32
+	foreign := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetched, 1)
+	}))
+	defer foreign.Close()
+	suite.addon.AllowedHosts = []string{"hipchat.example.com"}
+
+	for _, capabilitiesUrl := range []string{foreign.URL + "/v2/capabilities", "file:///etc/passwd", "://"} {
+		resp := suite.installWith("oauth-id", "oauth-secret", capabilitiesUrl)
+		suite.Equal(http.StatusForbidden, resp.StatusCode, capabilitiesUrl)
+	}
+	suite.Equal(int32(0), atomic.LoadInt32(&fetched))
+
+	// Only api.hipchat.com is allowed by default
+	suite.addon.AllowedHosts = nil
+	resp := suite.install()
+	suite.Equal(http.StatusForbidden, resp.StatusCode)
+
+	// The API URL of the capabilities must be allowed as well
+	suite.addon.AllowedHosts = []string{"127.0.0.1"}
+	resp = suite.installWith("oauth-id", "oauth-secret", suite.api.URL+"/v2/foreign-capabilities")
+	suite.Equal(http.StatusForbidden, resp.StatusCode)
+
+	_, err := suite.store.Get(context.Background(), "oauth-id")
+	suite.Equal(ErrNotInstalled, err)
+	suite.Equal(int32(0), atomic.LoadInt32(&suite.tokens))
+}
+
+func (suite *AddonTestSuite) TestInstall_invalid() {
+	for _, body := range []string{`{`, `{"oauthId":"oauth-id"}`} {
+		resp, err := http.Post(suite.addonServer.URL+"/installable", "application/json", strings.NewReader(body))
+		suite.Nil(err)
+		resp.Body.Close()
+		suite.Equal(http.StatusBadRequest, resp.StatusCode, body)
+	}
+
+	body := fmt.Sprintf(`{"oauthId":"oauth-id","oauthSecret":"oauth-secret","capabilitiesUrl":"%s/missing"}`, suite.api.URL)
+	resp, err := http.Post(suite.addonServer.URL+"/installable", "application/json", strings.NewReader(body))
+	suite.Nil(err)
+	resp.Body.Close()
+	suite.Equal(http.StatusBadGateway, resp.StatusCode)
+
+	_, err = suite.store.Get(context.Background(), "oauth-id")
+	suite.Equal(ErrNotInstalled, err)
+}
+
+func (suite *AddonTestSuite) TestUninstall() {
+	suite.install()
+
+	var uninstalled *Installation
+	suite.addon.OnUninstalled = func(ctx context.Context, installation *Installation) error {
+		uninstalled = installation
+		return nil
+	}
+
+	suite.revoke(http.StatusUnauthorized)
+	resp := suite.uninstall()
+	suite.Equal(http.StatusNoContent, resp.StatusCode)
+	suite.Equal("oauth-id", uninstalled.OAuthId)
+
+	_, err := suite.store.Get(context.Background(), "oauth-id")
+	suite.Equal(ErrNotInstalled, err)
+
+	// Retried callbacks succeed
+	resp = suite.uninstall()
+	suite.Equal(http.StatusNoContent, resp.StatusCode)
+}
+
+func (suite *AddonTestSuite) TestUninstall_callbackError() {
+	suite.install()
+	suite.addon.OnUninstalled = func(ctx context.Context, installation *Installation) error {
+		return errors.New("boom")
+	}
+
+	suite.revoke(http.StatusUnauthorized)
+	resp := suite.uninstall()
+	suite.Equal(http.StatusInternalServerError, resp.StatusCode)
+
+	_, err := suite.store.Get(context.Background(), "oauth-id")
+	suite.Nil(err)
+}
+
+func (suite *AddonTestSuite) TestUninstall_invalidClient() {
+	suite.install()
+
+	suite.revoke(http.StatusBadRequest)
+	resp := suite.uninstall()
+	suite.Equal(http.StatusNoContent, resp.StatusCode)
+
+	_, err := suite.store.Get(context.Background(), "oauth-id")
+	suite.Equal(ErrNotInstalled, err)
+}
+
+func (suite *AddonTestSuite) TestUninstall_forged() {
+	suite.install()
+	client, err := suite.addon.Client(context.Background(), "oauth-id")
+	suite.Nil(err)
+
+	uninstalls := 0
+	suite.addon.OnUninstalled = func(ctx context.Context, installation *Installation) error {
+		uninstalls++
+		return nil
+	}
+
+	// HipChat still generates tokens for the installation
+	resp := suite.uninstall()
+	suite.Equal(http.StatusForbidden, resp.StatusCode)
+
+	// HipChat can't tell, the callback is retried later
+	suite.revoke(http.StatusInternalServerError)
+	resp = suite.uninstall()
+	suite.Equal(http.StatusBadGateway, resp.StatusCode)
+
+	installation, err := suite.store.Get(context.Background(), "oauth-id")
+	suite.Nil(err)
+	suite.Equal("oauth-secret", installation.OAuthSecret)
+	suite.Equal(0, uninstalls)
+
+	again, err := suite.addon.Client(context.Background(), "oauth-id")
+	suite.Nil(err)
+	suite.True(client == again)
+}
+
+func (suite *AddonTestSuite) TestClient() {
+	suite.install()
+
+	client, err := suite.addon.Client(context.Background(), "oauth-id")
+	suite.Nil(err)
+	suite.Equal(suite.api.URL+"/v2", client.BaseUrl.String())
+	suite.Equal([]string{"send_notification", "view_room"}, client.TokenScopes())
+
+	for i := 0; i < 2; i++ {
+		_, err = client.Rooms.SendRoomNotification(context.Background(), "1", &hipchat.Notification{Message: "hi"})
+		suite.Nil(err)
+	}
+	// One token checked the installation, the client reuses another
+	suite.Equal(int32(2), atomic.LoadInt32(&suite.tokens))
+
+	again, err := suite.addon.Client(context.Background(), "oauth-id")
+	suite.Nil(err)
+	suite.True(client == again)
+
+	// Calls the scopes of the descriptor don't allow aren't sent
+	_, _, err = client.Users.ListUsers(context.Background(), nil)
+	suite.IsType(&hipchat.InsufficientScopeError{}, err)
+}
+
+func (suite *AddonTestSuite) TestClient_notInstalled() {
+	_, err := suite.addon.Client(context.Background(), "oauth-id")
+	suite.Equal(ErrNotInstalled, err)
+
+	suite.install()
+	_, err = suite.addon.Client(context.Background(), "oauth-id")
+	suite.Nil(err)
+
+	suite.revoke(http.StatusUnauthorized)
+	suite.uninstall()
+	_, err = suite.addon.Client(context.Background(), "oauth-id")
+	suite.Equal(ErrNotInstalled, err)
+}
+
+func (suite *AddonTestSuite) TestSecret() {
+	_, err := suite.addon.Secret(context.Background(), "oauth-id")
+	suite.Equal(ErrNotInstalled, err)
+
+	suite.install()
+	secret, err := suite.addon.Secret(context.Background(), "oauth-id")
+	suite.Nil(err)
+	suite.Equal("oauth-secret", secret)
+	suite.NotNil(suite.addon.Verifier())
+}
+
+func TestAddonTestSuite(t *testing.T) {
+	suite.Run(t, new(AddonTestSuite))
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	installation := &Installation{OAuthId: "id", OAuthSecret: "secret"}
+	assert.Nil(t, store.Save(ctx, installation))
+
+	// Stored installations are copies
+	installation.OAuthSecret = "changed"
+	got, err := store.Get(ctx, "id")
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", got.OAuthSecret)
+
+	assert.Nil(t, store.Delete(ctx, "id"))
+	assert.Nil(t, store.Delete(ctx, "id"))
+	_, err = store.Get(ctx, "id")
+	assert.Equal(t, ErrNotInstalled, err)
+}
@@ -0,0 +1,76 @@
+package addon
+
+import "github.com/theodesp/go-hipchat/hipchat"
+
+// Descriptor is the capabilities descriptor of an add-on, which HipChat reads
+// when the add-on is installed.
+type Descriptor struct {
+	// The unique key of the add-on, for example 'com.example.deploy-bot'.
+	Key string `json:"key"`
+
+	// The name of the add-on.
+	Name string `json:"name"`
+
+	// The description of the add-on.
+	Description string `json:"description"`
+
+	// The vendor of the add-on.
+	Vendor *Vendor `json:"vendor,omitempty"`
+
+	// URLs of the add-on
+	Links Links `json:"links"`
+
+	// The capabilities of the add-on.
+	Capabilities Capabilities `json:"capabilities"`
+}
+
+// Vendor represents the vendor of an add-on
+type Vendor struct {
+	// The name of the vendor.
+	Name string `json:"name"`
+
+	// The URL of the vendor.
+	Url string `json:"url"`
+}
+
+// Links represents the URLs of an add-on
+type Links struct {
+	// The URL the descriptor is served at.
+	Self string `json:"self"`
+
+	// The URL of the homepage of the add-on.
+	Homepage string `json:"homepage,omitempty"`
+}
+
+// Capabilities represents what an add-on does
+type Capabilities struct {
+	// The access the add-on needs to the HipChat API.
+	HipchatApiConsumer *ApiConsumer `json:"hipchatApiConsumer,omitempty"`
+
+	// How the add-on is installed.
+	Installable *Installable `json:"installable,omitempty"`
+
+	// The webhooks registered in the rooms the add-on is installed in.
+	Webhook []*hipchat.Webhook `json:"webhook,omitempty"`
+}
+
+// ApiConsumer represents the access an add-on needs to the HipChat API
+type ApiConsumer struct {
+	// The scopes the tokens of the add-on are granted.
+	Scopes []string `json:"scopes"`
+
+	// The label notifications sent by the add-on are shown with.
+	FromName string `json:"fromName,omitempty"`
+}
+
+// Installable represents how an add-on is installed
+type Installable struct {
+	// The URL HipChat sends the installed and uninstalled callbacks to.
+	CallbackUrl string `json:"callbackUrl"`
+
+	// Whether the add-on can be installed for the whole group.
+	AllowGlobal bool `json:"allowGlobal"`
+
+	// Whether the add-on can be installed in a room.
+	AllowRoom bool `json:"allowRoom"`
+}
@@ -0,0 +1,91 @@
+package addon
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrNotInstalled is returned by stores when no installation has the
+// requested oauth id.
+var ErrNotInstalled = errors.New("not_installed: no installation has this oauth id")
+
+// Installation represents an installation of an add-on in a group or room.
+type Installation struct {
+	// The oauth id of the installation.
+	OAuthId string `json:"oauthId"`
+
+	// The oauth secret of the installation, which tokens are generated with
+	// and callbacks are signed with.
+	OAuthSecret string `json:"oauthSecret"`
+
+	// The URL of the capabilities of the HipChat instance.
+	CapabilitiesUrl string `json:"capabilitiesUrl"`
+
+	// The id of the group the add-on is installed in.
+	GroupId int64 `json:"groupId"`
+
+	// The id of the room the add-on is installed in, if it's not installed
+	// for the whole group.
+	RoomId int64 `json:"roomId,omitempty"`
+
+	// The URL of the API of the HipChat instance, read from its capabilities.
+	ApiUrl string `json:"apiUrl,omitempty"`
+}
+
+// Store persists the installations of an add-on.
+type Store interface {
+	// Save creates or replaces an installation.
+	Save(ctx context.Context, installation *Installation) error
+
+	// Get returns the installation with the given oauth id, or
+	// ErrNotInstalled if there is none.
+	Get(ctx context.Context, oauthId string) (*Installation, error)
+
+	// Delete removes the installation with the given oauth id. Deleting an
+	// unknown installation is not an error.
+	Delete(ctx context.Context, oauthId string) error
+}
+
+// MemoryStore is a Store keeping installations in memory. It's meant for
+// tests and add-ons that can afford being reinstalled when restarted.
+type MemoryStore struct {
+	mu            sync.RWMutex
+	installations map[string]Installation
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{installations: make(map[string]Installation)}
+}
+
+// Save creates or replaces an installation.
+func (s *MemoryStore) Save(ctx context.Context, installation *Installation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.installations[installation.OAuthId] = *installation
+
+	return nil
+}
+
+// Get returns the installation with the given oauth id.
+func (s *MemoryStore) Get(ctx context.Context, oauthId string) (*Installation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	installation, ok := s.installations[oauthId]
+	if !ok {
+		return nil, ErrNotInstalled
+	}
+
+	return &installation, nil
+}
+
+// Delete removes the installation with the given oauth id.
+func (s *MemoryStore) Delete(ctx context.Context, oauthId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.installations, oauthId)
+
+	return nil
+}
@@ -0,0 +1,170 @@
+// Package bot routes the commands posted in HipChat rooms to their handlers.
+//
+// A Bot receives the room_message callbacks of a webhook.Handler, matches
+// them against the registered commands and replies through the HipChat API:
+//
+//	b := bot.New(client)
+//	b.Command("/deploy", "/deploy <service> deploys a service", func(c *bot.Context) error {
+//		if len(c.Args) == 0 {
+//			return c.Reply("Usage: /deploy <service>")
+//		}
+//		return c.Reply("Deploying " + c.Args[0])
+//	})
+//
+//	h := webhook.NewHandler()
+//	b.Register(h)
+//	http.Handle("/hipchat/webhook", h)
+//
+// Register a webhook for the room_message event, or for the pattern event with
+// a pattern matching the commands, to receive the commands of a room.
+package bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/theodesp/go-hipchat/hipchat"
+	"github.com/theodesp/go-hipchat/webhook"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// HelpCommand is the command the help generated from the commands of a Bot
+// is sent in response to.
+const HelpCommand = "/help"
+
+// HandlerFunc handles a command.
+type HandlerFunc func(c *Context) error
+
+// Middleware wraps a HandlerFunc, for example to check who may run a command.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+type command struct {
+	prefix  string
+	pattern *regexp.Regexp
+	help    string
+	handler HandlerFunc
+}
+
+// match returns the arguments of the command if it matches text.
+func (cmd *command) match(text string) ([]string, bool) {
+	if cmd.pattern != nil {
+		m := cmd.pattern.FindStringSubmatch(text)
+		if m == nil {
+			return nil, false
+		}
+		return m[1:], true
+	}
+
+	if len(text) < len(cmd.prefix) || !strings.EqualFold(text[:len(cmd.prefix)], cmd.prefix) {
+		return nil, false
+	}
+	rest := text[len(cmd.prefix):]
+	if rest != "" && !unicode.IsSpace(rune(rest[0])) {
+		return nil, false
+	}
+
+	return strings.Fields(rest), true
+}
+
+func (cmd *command) name() string {
+	if cmd.pattern != nil {
+		return cmd.pattern.String()
+	}
+
+	return cmd.prefix
+}
+
+// Bot dispatches the commands posted in rooms to the handlers registered for
+// them. Commands are matched in the order they were registered.
+type Bot struct {
+	client     *hipchat.Client
+	commands   []*command
+	middleware []Middleware
+}
+
+// New returns a Bot replying through client, with the help command registered.
+func New(client *hipchat.Client) *Bot {
+	b := &Bot{client: client}
+	b.Command(HelpCommand, HelpCommand+" lists the available commands", func(c *Context) error {
+		n := hipchat.NewNotification(b.Help())
+		n.MessageFormat = hipchat.MessageFormatText
+		n.Color = hipchat.ColorGray
+		return c.Notify(n)
+	})
+
+	return b
+}
+
+// Use adds middleware wrapping the handlers of every command.
+func (b *Bot) Use(middleware ...Middleware) {
+	b.middleware = append(b.middleware, middleware...)
+}
+
+// Command registers the handler of the command messages starting with prefix,
+// for example '/deploy'. The words following the prefix are passed as Args.
+func (b *Bot) Command(prefix string, help string, handler HandlerFunc) {
+	b.commands = append(b.commands, &command{prefix: prefix, help: help, handler: handler})
+}
+
+// CommandRegexp registers the handler of the command messages matching
+// pattern. The submatches of the pattern are passed as Args.
+func (b *Bot) CommandRegexp(pattern *regexp.Regexp, help string, handler HandlerFunc) {
+	b.commands = append(b.commands, &command{pattern: pattern, help: help, handler: handler})
+}
+
+// Help returns the help of the registered commands, one command per line.
+func (b *Bot) Help() string {
+	var buf bytes.Buffer
+	for _, cmd := range b.commands {
+		help := cmd.help
+		if help == "" {
+			help = cmd.name()
+		}
+		fmt.Fprintln(&buf, help)
+	}
+
+	return strings.TrimSpace(buf.String())
+}
+
+// Register makes the Bot handle the room_message events received by h.
+func (b *Bot) Register(h *webhook.Handler) {
+	h.OnRoomMessage(b.HandleMessage)
+}
+
+// HandleMessage runs the first command matching the message of e. Messages
+// matching no command are ignored.
+func (b *Bot) HandleMessage(ctx context.Context, e *webhook.RoomMessageEvent) error {
+	if e.Message == nil || e.Room == nil {
+		return nil
+	}
+
+	text := strings.TrimSpace(e.Message.Message)
+	for _, cmd := range b.commands {
+		args, ok := cmd.match(text)
+		if !ok {
+			continue
+		}
+
+		c := &Context{
+			Context: ctx,
+			Event:   e,
+			Sender:  e.Message.From,
+			Room:    e.Room,
+			Message: e.Message,
+			Command: cmd.name(),
+			Args:    args,
+			client:  b.client,
+		}
+
+		handler := cmd.handler
+		for i := len(b.middleware) - 1; i >= 0; i-- {
+			handler = b.middleware[i](handler)
+		}
+
+		return handler(c)
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/theodesp/go-hipchat/hipchat"
+	"github.com/theodesp/go-hipchat/webhook"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+type BotTestSuite struct {
+	suite.Suite
+	bot     *Bot
+	mux     *http.ServeMux
+	api     *httptest.Server
+	webhook *httptest.Server
+
+	replies       []string
+	notifications []*hipchat.Notification
+}
+
+func (suite *BotTestSuite) SetupTest() {
+	suite.replies = nil
+	suite.notifications = nil
+
+	// HipChat API the bot replies through
+	suite.mux = http.NewServeMux()
+	suite.mux.HandleFunc("/v2/room/2/reply", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			MessageId string `json:"parentMessageId"`
+			Message   string `json:"message"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		suite.Equal("m1", body.MessageId)
+		suite.replies = append(suite.replies, body.Message)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	suite.mux.HandleFunc("/v2/room/2/notification", func(w http.ResponseWriter, r *http.Request) {
+		n := new(hipchat.Notification)
+		json.NewDecoder(r.Body).Decode(n)
+		suite.notifications = append(suite.notifications, n)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	suite.api = httptest.NewServer(suite.mux)
+
+	client := hipchat.NewClient(nil)
+	client.BaseUrl, _ = url.Parse(suite.api.URL)
+	suite.bot = New(client)
+
+	h := webhook.NewHandler()
+	suite.bot.Register(h)
+	suite.webhook = httptest.NewServer(h)
+}
+
+func (suite *BotTestSuite) TearDownTest() {
+	suite.api.Close()
+	suite.webhook.Close()
+}
+
+func TestBotTestSuite(t *testing.T) {
+	suite.Run(t, new(BotTestSuite))
+}
+
+// send posts a room_message callback from mentionName to the bot.
+func (suite *BotTestSuite) send(mentionName string, message string) int {
+	event := fmt.Sprintf(`{"event":"room_message","item":{
+		"message":{"id":"m1","type":"message","message":%q,"from":{"id":1,"mention_name":%q,"name":"User"}},
+		"room":{"id":2,"name":"Ops"}}}`, message, mentionName)
+
+	resp, err := http.Post(suite.webhook.URL, "application/json", strings.NewReader(event))
+	if err != nil {
+		suite.FailNow(err.Error())
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode
+}
+
+func (suite *BotTestSuite) TestBot_Command() {
+	assert := assert.New(suite.T())
+
+	var ctx *Context
+	suite.bot.Command("/deploy", "/deploy <service> <env>", func(c *Context) error {
+		ctx = c
+		return c.Reply("Deploying " + strings.Join(c.Args, " to "))
+	})
+
+	assert.Equal(http.StatusNoContent, suite.send("theo", "  /DEPLOY api   staging "))
+	assert.Equal([]string{"Deploying api to staging"}, suite.replies)
+
+	assert.Equal("/deploy", ctx.Command)
+	assert.Equal([]string{"api", "staging"}, ctx.Args)
+	assert.Equal("theo", ctx.Sender.MentionName)
+	assert.Equal(int64(2), ctx.Room.Id)
+	assert.Equal("m1", ctx.Message.Id)
+	assert.NotNil(ctx.Client())
+
+	// Messages merely starting like a command are ignored
+	ctx = nil
+	assert.Equal(http.StatusNoContent, suite.send("theo", "/deployment api"))
+	assert.Equal(http.StatusNoContent, suite.send("theo", "let's /deploy api"))
+	assert.Nil(ctx)
+	assert.Len(suite.replies, 1)
+}
+
+func (suite *BotTestSuite) TestBot_CommandRegexp() {
+	assert := assert.New(suite.T())
+
+	suite.bot.CommandRegexp(regexp.MustCompile(`^rollback (\w+) to v(\d+)$`), "rollback <service> to v<version>", func(c *Context) error {
+		n := hipchat.NewNotification(fmt.Sprintf("Rolling back %v to %v", c.Args[0], c.Args[1]))
+		n.Color = hipchat.ColorRed
+		return c.Notify(n)
+	})
+
+	assert.Equal(http.StatusNoContent, suite.send("theo", "rollback api to v42"))
+	assert.Len(suite.notifications, 1)
+	assert.Equal("Rolling back api to 42", suite.notifications[0].Message)
+	assert.Equal(hipchat.ColorRed, suite.notifications[0].Color)
+}
+
+func (suite *BotTestSuite) TestBot_Help() {
+	assert := assert.New(suite.T())
+
+	suite.bot.Command("/deploy", "/deploy <service> deploys a service", func(c *Context) error { return nil })
+	suite.bot.CommandRegexp(regexp.MustCompile(`^rollback (\w+)$`), "", func(c *Context) error { return nil })
+
+	want := "/help lists the available commands\n/deploy <service> deploys a service\n^rollback (\\w+)$"
+	assert.Equal(want, suite.bot.Help())
+
+	assert.Equal(http.StatusNoContent, suite.send("theo", "/help"))
+	assert.Len(suite.notifications, 1)
+	assert.Equal(want, suite.notifications[0].Message)
+	assert.Equal(hipchat.MessageFormatText, suite.notifications[0].MessageFormat)
+}
+
+func (suite *BotTestSuite) TestBot_Middleware() {
+	assert := assert.New(suite.T())
+
+	var calls []string
+	suite.bot.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			calls = append(calls, "first")
+			return next(c)
+		}
+	}, func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			calls = append(calls, "second")
+			return next(c)
+		}
+	})
+	suite.bot.Command("/deploy", "", AllowMentionNames("@Theo", "alex")(func(c *Context) error {
+		calls = append(calls, "deploy")
+		return nil
+	}))
+
+	assert.Equal(http.StatusNoContent, suite.send("theo", "/deploy api"))
+	assert.Equal([]string{"first", "second", "deploy"}, calls)
+
+	calls = nil
+	assert.Equal(http.StatusNoContent, suite.send("mallory", "/deploy api"))
+	assert.Equal([]string{"first", "second"}, calls)
+	assert.Equal([]string{"You are not allowed to run /deploy"}, suite.replies)
+}
+
+func (suite *BotTestSuite) TestBot_HandlerError() {
+	assert := assert.New(suite.T())
+
+	suite.bot.Command("/deploy", "", func(c *Context) error {
+		return errors.New("deploy failed")
+	})
+
+	assert.Equal(http.StatusInternalServerError, suite.send("theo", "/deploy api"))
+	assert.Nil(suite.bot.HandleMessage(context.Background(), &webhook.RoomMessageEvent{}))
+}
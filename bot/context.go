@@ -0,0 +1,61 @@
+package bot
+
+import (
+	"context"
+	"github.com/theodesp/go-hipchat/hipchat"
+	"github.com/theodesp/go-hipchat/webhook"
+	"strconv"
+)
+
+// Context carries a command being handled. It's canceled when the webhook
+// request the command was received with is.
+type Context struct {
+	context.Context
+
+	// The event the command was received with.
+	Event *webhook.RoomMessageEvent
+
+	// The user that sent the command.
+	Sender *hipchat.UserListItem
+
+	// The room the command was sent in.
+	Room *hipchat.Room
+
+	// The message carrying the command.
+	Message *hipchat.HistoryMessage
+
+	// The prefix or pattern of the matched command.
+	Command string
+
+	// The arguments of the command: the words following the prefix, or the
+	// submatches of the pattern.
+	Args []string
+
+	client *hipchat.Client
+}
+
+// Client returns the client the Bot replies through.
+func (c *Context) Client() *hipchat.Client {
+	return c.client
+}
+
+// Reply replies to the message carrying the command.
+//
+// Replies require a token with scope send_message, accessible by users.
+func (c *Context) Reply(message string) error {
+	_, err := c.client.Rooms.ReplyToRoomMessage(c, c.roomId(), c.Message.Id, message)
+	return err
+}
+
+// Notify sends a notification to the room the command was sent in.
+//
+// Notifications require a token with scope send_notification, accessible by
+// group clients, room clients and users.
+func (c *Context) Notify(notification *hipchat.Notification) error {
+	_, err := c.client.Rooms.SendRoomNotification(c, c.roomId(), notification)
+	return err
+}
+
+func (c *Context) roomId() string {
+	return strconv.FormatInt(c.Room.Id, 10)
+}
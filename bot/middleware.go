@@ -0,0 +1,25 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AllowMentionNames returns middleware letting only the users with one of the
+// given mention names run commands. Other users are told so in a reply.
+func AllowMentionNames(mentionNames ...string) Middleware {
+	allowed := make(map[string]bool, len(mentionNames))
+	for _, name := range mentionNames {
+		allowed[strings.ToLower(strings.TrimPrefix(name, "@"))] = true
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if c.Sender == nil || !allowed[strings.ToLower(c.Sender.MentionName)] {
+				return c.Reply(fmt.Sprintf("You are not allowed to run %v", c.Command))
+			}
+
+			return next(c)
+		}
+	}
+}
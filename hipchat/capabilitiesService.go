@@ -0,0 +1,152 @@
+package hipchat
+
+import (
+	"context"
+	"sort"
+)
+
+const (
+	getCapabilitiesRoute = "capabilities"
+)
+
+// CapabilitiesService handles communication with the capabilities related
+// methods of the HipChat API.
+type CapabilitiesService service
+
+// Capabilities represents the capabilities descriptor of a HipChat instance
+type Capabilities struct {
+	// The key of the instance, 'hipchat'.
+	Key string `json:"key"`
+
+	// The name of the instance.
+	Name string `json:"name"`
+
+	// The description of the instance.
+	Description string `json:"description"`
+
+	// The version of the Atlassian Connect server API the instance supports.
+	ConnectServerApiVersion int `json:"connect_server_api_version"`
+
+	// The vendor of the instance.
+	Vendor *CapabilitiesVendor `json:"vendor,omitempty"`
+
+	// URLs of the instance
+	Links *CapabilitiesLinks `json:"links,omitempty"`
+
+	// The capabilities of the instance.
+	Capabilities *CapabilitiesProviders `json:"capabilities,omitempty"`
+}
+
+// CapabilitiesVendor represents the vendor of a HipChat instance
+type CapabilitiesVendor struct {
+	// The name of the vendor.
+	Name string `json:"name"`
+
+	// The URL of the vendor.
+	Url string `json:"url"`
+}
+
+// CapabilitiesLinks represents the URLs of a HipChat instance
+type CapabilitiesLinks struct {
+	// The URL to use to retrieve the capabilities.
+	Self string `json:"self"`
+
+	// The URL of the API.
+	Api string `json:"api"`
+
+	// The URL of the homepage of the instance.
+	Homepage string `json:"homepage,omitempty"`
+}
+
+// CapabilitiesProviders represents the providers a HipChat instance exposes
+type CapabilitiesProviders struct {
+	// The REST API of the instance.
+	HipchatApiProvider *ApiProvider `json:"hipchatApiProvider,omitempty"`
+
+	// The OAuth 2 provider of the instance.
+	OAuth2Provider *OAuth2Provider `json:"oauth2Provider,omitempty"`
+}
+
+// ApiProvider represents the REST API of a HipChat instance
+type ApiProvider struct {
+	// The base URL of the API.
+	Url string `json:"url"`
+
+	// The scopes tokens can be granted, by id.
+	AvailableScopes map[string]*ScopeDescriptor `json:"availableScopes,omitempty"`
+}
+
+// ScopeDescriptor represents an OAuth scope supported by a HipChat instance
+type ScopeDescriptor struct {
+	// The id of the scope, for example 'send_notification'.
+	Id string `json:"id"`
+
+	// The name of the scope.
+	Name string `json:"name"`
+
+	// The description of what the scope allows.
+	Description string `json:"description"`
+}
+
+// OAuth2Provider represents the OAuth 2 endpoints of a HipChat instance
+type OAuth2Provider struct {
+	// The URL users authorize clients at.
+	AuthorizationUrl string `json:"authorizationUrl"`
+
+	// The URL tokens are generated at.
+	TokenUrl string `json:"tokenUrl"`
+}
+
+// Scopes returns the ids of the scopes supported by the instance, sorted.
+func (c *Capabilities) Scopes() []string {
+	if c.Capabilities == nil || c.Capabilities.HipchatApiProvider == nil {
+		return nil
+	}
+
+	var scopes []string
+	for id := range c.Capabilities.HipchatApiProvider.AvailableScopes {
+		scopes = append(scopes, id)
+	}
+	sort.Strings(scopes)
+
+	return scopes
+}
+
+// SupportsScope reports whether the instance supports the given scope.
+func (c *Capabilities) SupportsScope(scope string) bool {
+	if c.Capabilities == nil || c.Capabilities.HipchatApiProvider == nil {
+		return false
+	}
+
+	_, ok := c.Capabilities.HipchatApiProvider.AvailableScopes[scope]
+	return ok
+}
+
+// OAuth2Urls returns the URLs of the OAuth 2 provider of the instance, for
+// example to build an oauth2.Endpoint. They are empty if the instance has no
+// OAuth 2 provider.
+func (c *Capabilities) OAuth2Urls() (authorizationUrl string, tokenUrl string) {
+	if c.Capabilities == nil || c.Capabilities.OAuth2Provider == nil {
+		return "", ""
+	}
+
+	return c.Capabilities.OAuth2Provider.AuthorizationUrl, c.Capabilities.OAuth2Provider.TokenUrl
+}
+
+// Gets the capabilities descriptor of the instance.
+//
+// Authentication not required.
+func (s *CapabilitiesService) GetCapabilities(ctx context.Context) (*Capabilities, *PaginatedResponse, error) {
+	req, err := s.client.Get(getCapabilitiesRoute)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	capabilities := new(Capabilities)
+	resp, err := s.client.Do(ctx, req, capabilities)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return capabilities, resp, nil
+}
@@ -0,0 +1,69 @@
+package hipchat
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+)
+
+func (suite *HipChatClientTestSuite) TestCapabilitiesService_GetCapabilities() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf("/%s/%s", apiVersion2, getCapabilitiesRoute)
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodGet)
+		fmt.Fprint(w, `{
+			"capabilities": {
+				"hipchatApiProvider": {
+					"availableScopes": {
+						"view_room": {"id": "view_room", "name": "View Room", "description": "View room information and participants"},
+						"send_notification": {"id": "send_notification", "name": "Send Notification", "description": "Send room notifications"}
+					},
+					"url": "https://hipchat.example.com/v2/"
+				},
+				"oauth2Provider": {
+					"authorizationUrl": "https://hipchat.example.com/users/authorize",
+					"tokenUrl": "https://hipchat.example.com/v2/oauth/token"
+				}
+			},
+			"connect_server_api_version": 1,
+			"description": "Group chat and IM built for teams",
+			"key": "hipchat",
+			"links": {"api": "https://hipchat.example.com/v2", "homepage": "https://hipchat.example.com", "self": "https://hipchat.example.com/v2/capabilities"},
+			"name": "HipChat",
+			"vendor": {"name": "Atlassian", "url": "http://atlassian.com"}
+		}`)
+	})
+
+	capabilities, _, err := suite.client.Capabilities.GetCapabilities(context.Background())
+	assert.Nil(err)
+
+	assert.Equal("hipchat", capabilities.Key)
+	assert.Equal("HipChat", capabilities.Name)
+	assert.Equal(1, capabilities.ConnectServerApiVersion)
+	assert.Equal(&CapabilitiesVendor{Name: "Atlassian", Url: "http://atlassian.com"}, capabilities.Vendor)
+	assert.Equal("https://hipchat.example.com/v2", capabilities.Links.Api)
+	assert.Equal("https://hipchat.example.com/v2/", capabilities.Capabilities.HipchatApiProvider.Url)
+	assert.Equal(&ScopeDescriptor{Id: "view_room", Name: "View Room", Description: "View room information and participants"},
+		capabilities.Capabilities.HipchatApiProvider.AvailableScopes[ScopeViewRoom])
+
+	assert.Equal([]string{ScopeSendNotification, ScopeViewRoom}, capabilities.Scopes())
+	assert.True(capabilities.SupportsScope(ScopeViewRoom))
+	assert.False(capabilities.SupportsScope(ScopeAdminGroup))
+
+	authorizationUrl, tokenUrl := capabilities.OAuth2Urls()
+	assert.Equal("https://hipchat.example.com/users/authorize", authorizationUrl)
+	assert.Equal("https://hipchat.example.com/v2/oauth/token", tokenUrl)
+}
+
+func (suite *HipChatClientTestSuite) TestCapabilities_withoutProviders() {
+	assert := assert.New(suite.T())
+	capabilities := &Capabilities{}
+
+	assert.Nil(capabilities.Scopes())
+	assert.False(capabilities.SupportsScope(ScopeViewRoom))
+	authorizationUrl, tokenUrl := capabilities.OAuth2Urls()
+	assert.Empty(authorizationUrl)
+	assert.Empty(tokenUrl)
+}
@@ -0,0 +1,231 @@
+package hipchat
+
+const (
+	// Card styles
+	CardStyleFile        = "file"
+	CardStyleImage       = "image"
+	CardStyleApplication = "application"
+	CardStyleLink        = "link"
+	CardStyleMedia       = "media"
+
+	// Card display formats
+	CardFormatCompact = "compact"
+	CardFormatMedium  = "medium"
+
+	// Card attribute value styles
+	CardAttributeStyleLozenge         = "lozenge"
+	CardAttributeStyleLozengeSuccess  = "lozenge-success"
+	CardAttributeStyleLozengeError    = "lozenge-error"
+	CardAttributeStyleLozengeCurrent  = "lozenge-current"
+	CardAttributeStyleLozengeComplete = "lozenge-complete"
+	CardAttributeStyleLozengeMoved    = "lozenge-moved"
+)
+
+// Card represents a HipChat notification Card
+type Card struct {
+	// Type of the card.
+	// Valid values: file, image, application, link, media.
+	Style string `json:"style"`
+
+	// An id that will help HipChat recognise the same card when it is sent multiple times.
+	Id string `json:"id"`
+
+	// The title of the card. Valid length range: 1 - 500.
+	Title string `json:"title"`
+
+	// The description of the card.
+	Description *CardDescription `json:"description,omitempty"`
+
+	// Application cards can be compact (1 to 2 lines) or medium (1 to 5 lines).
+	// Valid values: compact, medium.
+	Format string `json:"format,omitempty"`
+
+	// The url where the card will open.
+	Url string `json:"url,omitempty"`
+
+	// The thumbnail of the card. Required for image and media cards.
+	Thumbnail *CardThumbnail `json:"thumbnail,omitempty"`
+
+	// The activity will generate a collapsable card of one line showing the html
+	// and the ability to maximize to see all the content.
+	Activity *CardActivity `json:"activity,omitempty"`
+
+	// List of attributes to show below the card. Sample {label}:{value.icon} {value.label}.
+	Attributes []*CardAttribute `json:"attributes,omitempty"`
+
+	// The icon of the card.
+	Icon *CardIcon `json:"icon,omitempty"`
+}
+
+// CardDescription represents the description of a HipChat Card
+type CardDescription struct {
+	// The description value. Valid length range: 1 - 1000.
+	Value string `json:"value"`
+
+	// Determines how the description is rendered.
+	// Valid values: html, text.
+	Format string `json:"format"`
+}
+
+// CardThumbnail represents the thumbnail of a HipChat Card
+type CardThumbnail struct {
+	// The thumbnail url. Valid length range: 1 - 250.
+	Url string `json:"url"`
+
+	// The thumbnail url in retina. Valid length range: 1 - 250.
+	Url2x string `json:"url@2x,omitempty"`
+
+	// The original width of the image.
+	Width int `json:"width,omitempty"`
+
+	// The original height of the image.
+	Height int `json:"height,omitempty"`
+}
+
+// CardActivity represents the activity section of a HipChat Card
+type CardActivity struct {
+	// Html for the activity to show in one line a summary of the action that happened.
+	Html string `json:"html"`
+
+	// The activity icon.
+	Icon *CardIcon `json:"icon,omitempty"`
+}
+
+// CardIcon represents an icon of a HipChat Card
+type CardIcon struct {
+	// The url where the icon is. Valid length range: 1 - 250.
+	Url string `json:"url"`
+
+	// The url for the icon in retina. Valid length range: 1 - 250.
+	Url2x string `json:"url@2x,omitempty"`
+}
+
+// CardAttribute represents an attribute shown below a HipChat Card
+type CardAttribute struct {
+	// Attribute label. Valid length range: 1 - 50.
+	Label string `json:"label,omitempty"`
+
+	Value *CardAttributeValue `json:"value"`
+}
+
+// CardAttributeValue represents the value of a HipChat Card attribute
+type CardAttributeValue struct {
+	// The text representation of the value. Valid length range: 1 - 50.
+	Label string `json:"label"`
+
+	// Url to be opened when a user clicks on the label.
+	Url string `json:"url,omitempty"`
+
+	// AUI Integrations for now supporting only lozenges.
+	// Valid values: lozenge-success, lozenge-error, lozenge-current, lozenge-complete, lozenge-moved, lozenge.
+	Style string `json:"style,omitempty"`
+
+	// The icon of the value.
+	Icon *CardIcon `json:"icon,omitempty"`
+}
+
+// Validate checks that the card carries the fields required by its style.
+func (c *Card) Validate() error {
+	switch c.Style {
+	case CardStyleFile, CardStyleLink:
+		if c.Url == "" {
+			return missingCardField(c.Style, "url")
+		}
+	case CardStyleImage:
+		if c.Thumbnail == nil || c.Thumbnail.Url == "" {
+			return missingCardField(c.Style, "thumbnail.url")
+		}
+	case CardStyleMedia:
+		if c.Url == "" {
+			return missingCardField(c.Style, "url")
+		}
+		if c.Thumbnail == nil || c.Thumbnail.Url == "" {
+			return missingCardField(c.Style, "thumbnail.url")
+		}
+	case CardStyleApplication:
+	default:
+		return invalidCardStyle
+	}
+
+	if c.Id == "" {
+		return missingCardField(c.Style, "id")
+	}
+	if c.Title == "" {
+		return missingCardField(c.Style, "title")
+	}
+	if c.Description != nil && c.Description.Value == "" {
+		return missingCardField(c.Style, "description.value")
+	}
+	if c.Activity != nil && c.Activity.Html == "" {
+		return missingCardField(c.Style, "activity.html")
+	}
+	for _, attr := range c.Attributes {
+		if attr.Value == nil || attr.Value.Label == "" {
+			return missingCardField(c.Style, "attributes.value.label")
+		}
+	}
+
+	return nil
+}
+
+// CardBuilder builds a Card step by step, validating it once Build is called.
+type CardBuilder struct {
+	card *Card
+}
+
+// Creates a new CardBuilder for a card of the given style
+func NewCardBuilder(style string, id string, title string) *CardBuilder {
+	return &CardBuilder{card: &Card{Style: style, Id: id, Title: title}}
+}
+
+// Description sets the card description and the format it is rendered with.
+func (b *CardBuilder) Description(value string, format string) *CardBuilder {
+	b.card.Description = &CardDescription{Value: value, Format: format}
+	return b
+}
+
+// Format sets the card display format.
+func (b *CardBuilder) Format(format string) *CardBuilder {
+	b.card.Format = format
+	return b
+}
+
+// Url sets the url the card opens.
+func (b *CardBuilder) Url(url string) *CardBuilder {
+	b.card.Url = url
+	return b
+}
+
+// Thumbnail sets the card thumbnail.
+func (b *CardBuilder) Thumbnail(thumbnail *CardThumbnail) *CardBuilder {
+	b.card.Thumbnail = thumbnail
+	return b
+}
+
+// Activity sets the one line activity html of the card and its optional icon.
+func (b *CardBuilder) Activity(html string, icon *CardIcon) *CardBuilder {
+	b.card.Activity = &CardActivity{Html: html, Icon: icon}
+	return b
+}
+
+// Attribute appends an attribute to the card.
+func (b *CardBuilder) Attribute(label string, value *CardAttributeValue) *CardBuilder {
+	b.card.Attributes = append(b.card.Attributes, &CardAttribute{Label: label, Value: value})
+	return b
+}
+
+// Icon sets the card icon.
+func (b *CardBuilder) Icon(url string, url2x string) *CardBuilder {
+	b.card.Icon = &CardIcon{Url: url, Url2x: url2x}
+	return b
+}
+
+// Build validates and returns the card.
+func (b *CardBuilder) Build() (*Card, error) {
+	if err := b.card.Validate(); err != nil {
+		return nil, err
+	}
+
+	c := *b.card
+	return &c, nil
+}
@@ -0,0 +1,119 @@
+package hipchat
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type HipChatCardTestSuite struct {
+	suite.Suite
+}
+
+func TestHipChatCardTestSuite(t *testing.T) {
+	suite.Run(t, new(HipChatCardTestSuite))
+}
+
+func encodeCard(v interface{}) string {
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+	return buf.String()
+}
+
+func (suite *HipChatCardTestSuite) TestCardBuilder_Golden() {
+	assert := assert.New(suite.T())
+	icon := &CardIcon{Url: "http://i.co/i.png"}
+	testCases := []struct {
+		name    string
+		builder *CardBuilder
+		want    string
+	}{
+		{
+			"TestApplicationCard",
+			NewCardBuilder(CardStyleApplication, "1", "Build #42").
+				Format(CardFormatMedium).
+				Url("http://ci.co/42").
+				Description("<b>passed</b>", MessageFormatHtml).
+				Attribute("status", &CardAttributeValue{Label: "ok", Style: CardAttributeStyleLozengeSuccess}).
+				Icon("http://i.co/i.png", "http://i.co/i@2x.png"),
+			`{"style":"application","id":"1","title":"Build #42","description":{"value":"<b>passed</b>","format":"html"},"format":"medium","url":"http://ci.co/42","attributes":[{"label":"status","value":{"label":"ok","style":"lozenge-success"}}],"icon":{"url":"http://i.co/i.png","url@2x":"http://i.co/i@2x.png"}}` + "\n",
+		},
+		{
+			"TestActivityCard",
+			NewCardBuilder(CardStyleApplication, "2", "Deploy").
+				Format(CardFormatCompact).
+				Activity("<b>theo</b> deployed", icon),
+			`{"style":"application","id":"2","title":"Deploy","format":"compact","activity":{"html":"<b>theo</b> deployed","icon":{"url":"http://i.co/i.png"}}}` + "\n",
+		},
+		{
+			"TestImageCard",
+			NewCardBuilder(CardStyleImage, "3", "Screenshot").
+				Thumbnail(&CardThumbnail{Url: "http://i.co/s.png", Width: 100, Height: 50}),
+			`{"style":"image","id":"3","title":"Screenshot","thumbnail":{"url":"http://i.co/s.png","width":100,"height":50}}` + "\n",
+		},
+		{
+			"TestLinkCard",
+			NewCardBuilder(CardStyleLink, "4", "Docs").
+				Url("http://docs.co").
+				Description("read me", MessageFormatText),
+			`{"style":"link","id":"4","title":"Docs","description":{"value":"read me","format":"text"},"url":"http://docs.co"}` + "\n",
+		},
+		{
+			"TestMediaCard",
+			NewCardBuilder(CardStyleMedia, "5", "Demo").
+				Url("http://v.co/demo.mp4").
+				Thumbnail(&CardThumbnail{Url: "http://v.co/demo.png"}),
+			`{"style":"media","id":"5","title":"Demo","url":"http://v.co/demo.mp4","thumbnail":{"url":"http://v.co/demo.png"}}` + "\n",
+		},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			card, err := tc.builder.Build()
+			assert.Nil(err)
+			assert.Equal(tc.want, encodeCard(card))
+		})
+	}
+}
+
+func (suite *HipChatCardTestSuite) TestCardBuilder_Validation() {
+	assert := assert.New(suite.T())
+	testCases := []struct {
+		name    string
+		builder *CardBuilder
+		want    error
+	}{
+		{"TestUnknownStyle", NewCardBuilder("activity", "1", "t"), invalidCardStyle},
+		{"TestMissingId", NewCardBuilder(CardStyleApplication, "", "t"), missingCardField(CardStyleApplication, "id")},
+		{"TestMissingTitle", NewCardBuilder(CardStyleApplication, "1", ""), missingCardField(CardStyleApplication, "title")},
+		{"TestActivityWithoutHtml", NewCardBuilder(CardStyleApplication, "1", "t").Activity("", nil), missingCardField(CardStyleApplication, "activity.html")},
+		{"TestImageWithoutThumbnail", NewCardBuilder(CardStyleImage, "1", "t"), missingCardField(CardStyleImage, "thumbnail.url")},
+		{"TestLinkWithoutUrl", NewCardBuilder(CardStyleLink, "1", "t"), missingCardField(CardStyleLink, "url")},
+		{"TestFileWithoutUrl", NewCardBuilder(CardStyleFile, "1", "t"), missingCardField(CardStyleFile, "url")},
+		{"TestMediaWithoutThumbnail", NewCardBuilder(CardStyleMedia, "1", "t").Url("http://v.co"), missingCardField(CardStyleMedia, "thumbnail.url")},
+		{"TestAttributeWithoutLabel", NewCardBuilder(CardStyleApplication, "1", "t").Attribute("a", &CardAttributeValue{}), missingCardField(CardStyleApplication, "attributes.value.label")},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			card, err := tc.builder.Build()
+			assert.Nil(card)
+			assert.EqualError(err, tc.want.Error())
+		})
+	}
+}
+
+func (suite *HipChatCardTestSuite) TestNotification_withCard() {
+	assert := assert.New(suite.T())
+
+	card, err := NewCardBuilder(CardStyleLink, "1", "Docs").Url("http://docs.co").Build()
+	assert.Nil(err)
+
+	n := NewNotification("docs")
+	n.Card = card
+
+	want := `{"message_format":"html","color":"yellow","message":"docs","card":{"style":"link","id":"1","title":"Docs","url":"http://docs.co"}}` + "\n"
+	assert.Equal(want, encodeCard(n))
+}
@@ -4,7 +4,15 @@ const (
 	defaultBaseUrl             = "https://api.hipchat.com/"
 	userAgent                  = "go-hipchat"
 	contentTypeApplicationJson = "application/json; charset=UTF-8"
+	contentTypeForm            = "application/x-www-form-urlencoded"
 	contentDispositionMetadata = `attachment; name="metadata"`
 	contentDispositionFile     = `attachment; name="file"; filename="%v"`
 	apiVersion2                = "v2"
+
+	headerRateLimit     = "X-Ratelimit-Limit"
+	headerRateRemaining = "X-Ratelimit-Remaining"
+	headerRateReset     = "X-Ratelimit-Reset"
 )
+
+// MaxFileSize is the largest file, in bytes, HipChat accepts for sharing.
+const MaxFileSize = 50 << 20
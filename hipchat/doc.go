@@ -52,5 +52,12 @@ https://developer.atlassian.com/server/hipchat/hipchat-rest-api-response-codes
 
 Pagination:
 
+All requests for resource collections (rooms, users, etc.) support pagination
+through the ListOptions struct. Every response exposes the links to the
+previous and next pages in PaginatedResponse.Links. Rather than walking those
+links by hand, the rooms of a group can be iterated with RoomsService.ListAllRooms,
+which fetches the pages lazily. Any other list endpoint can be walked with a
+PageIterator.
+
 */
 package hipchat
@@ -0,0 +1,158 @@
+package hipchat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	// Emoticon types
+	EmoticonTypeAll    = "all"
+	EmoticonTypeGroup  = "group"
+	EmoticonTypeGlobal = "global"
+
+	listEmoticonsRoute     = "emoticon"
+	getEmoticonRoute       = "emoticon/%v"
+	listRoomEmoticonsRoute = "room/%v/emoticon"
+)
+
+// EmoticonsService handles communication with the emoticon related
+// methods of the HipChat API.
+type EmoticonsService service
+
+// Emoticon represents a HipChat Emoticon
+type Emoticon struct {
+	// The id of the emoticon.
+	Id int64 `json:"id"`
+
+	// The shortcut of the emoticon, used in messages as '(shortcut)'.
+	Shortcut string `json:"shortcut"`
+
+	// The URL of the emoticon image.
+	Url string `json:"url"`
+
+	// The width of the image in pixels. Only set when getting a single emoticon.
+	Width int `json:"width,omitempty"`
+
+	// The height of the image in pixels. Only set when getting a single emoticon.
+	Height int `json:"height,omitempty"`
+
+	// The path of the sound played along with the emoticon, if any.
+	AudioPath string `json:"audio_path,omitempty"`
+
+	// The type of the emoticon.
+	// Valid values: group, global.
+	Type string `json:"type,omitempty"`
+
+	// The user that created the emoticon. Only set for group emoticons.
+	Creator *UserListItem `json:"creator,omitempty"`
+
+	// URLs to retrieve emoticon information
+	Links *struct {
+		// The URL to use to retrieve the full emoticon information
+		Self string `json:"self"`
+	} `json:"links,omitempty"`
+}
+
+// EmoticonsListOptions specifies the optional parameters to the
+// EmoticonsService.ListEmoticons
+type EmoticonsListOptions struct {
+	// The type of emoticons to get.
+	// Valid values: all, group, global.
+	//
+	// Defaults to 'all'.
+	Type string `url:"type,omitempty"`
+	ListOptions
+}
+
+// List emoticons for this group.
+//
+// Authentication required, with scope view_group.
+// Accessible by group clients, room clients, users.
+func (s *EmoticonsService) ListEmoticons(ctx context.Context, opt *EmoticonsListOptions) ([]*Emoticon, *PaginatedResponse, error) {
+	if err := s.client.checkScope(opEmoticonsListEmoticons); err != nil {
+		return nil, nil, err
+	}
+
+	return s.listEmoticons(ctx, listEmoticonsRoute, opt)
+}
+
+// Get an emoticon by id or shortcut. The shortcut may be given with or
+// without its surrounding parentheses.
+//
+// Authentication required, with scope view_group.
+// Accessible by group clients, room clients, users.
+func (s *EmoticonsService) GetEmoticon(ctx context.Context, emoticonIdOrShortcut string) (*Emoticon, *PaginatedResponse, error) {
+	if err := s.client.checkScope(opEmoticonsGetEmoticon); err != nil {
+		return nil, nil, err
+	}
+
+	emoticonIdOrShortcut = strings.TrimSuffix(strings.TrimPrefix(emoticonIdOrShortcut, "("), ")")
+	if emoticonIdOrShortcut == "" {
+		return nil, nil, emptyParam
+	}
+
+	u := fmt.Sprintf(getEmoticonRoute, emoticonIdOrShortcut)
+	req, err := s.client.Get(u)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	emoticon := new(Emoticon)
+	resp, err := s.client.Do(ctx, req, emoticon)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return emoticon, resp, nil
+}
+
+// List the custom emoticons available in a room.
+//
+// Authentication required, with scope view_room.
+// Accessible by group clients, room clients, users.
+func (s *EmoticonsService) ListRoomEmoticons(ctx context.Context, roomIdOrName string, opt *EmoticonsListOptions) ([]*Emoticon, *PaginatedResponse, error) {
+	if err := s.client.checkScope(opEmoticonsListRoomEmoticons); err != nil {
+		return nil, nil, err
+	}
+
+	var u, err = getRoomResourcePath(roomIdOrName, listRoomEmoticonsRoute)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s.listEmoticons(ctx, u, opt)
+}
+
+func (s *EmoticonsService) listEmoticons(ctx context.Context, u string, opt *EmoticonsListOptions) ([]*Emoticon, *PaginatedResponse, error) {
+	if opt != nil {
+		switch opt.Type {
+		case "", EmoticonTypeAll, EmoticonTypeGroup, EmoticonTypeGlobal:
+		default:
+			return nil, nil, invalidEmoticonType
+		}
+	}
+
+	opts, err := addUrlOptions(u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.Get(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var emoticons *emoticonsListResponse
+	resp, err := s.client.Do(ctx, req, &emoticons)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return emoticons.Items, resp, nil
+}
+
+type emoticonsListResponse struct {
+	Items []*Emoticon `json:"items,omitempty"`
+}
@@ -0,0 +1,88 @@
+package hipchat
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+)
+
+func (suite *HipChatClientTestSuite) TestEmoticonsService_ListEmoticons() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf("/%s/%s", apiVersion2, listEmoticonsRoute)
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodGet)
+		assert.Equal("max-results=2&start-index=4&type=group", r.URL.RawQuery)
+		fmt.Fprint(w, `{"items":[
+			{"id":1,"shortcut":"shipit","url":"https://e.co/shipit.png","links":{"self":"https://api.hipchat.com/v2/emoticon/1"}},
+			{"id":2,"shortcut":"yey","url":"https://e.co/yey.png"}],
+			"startIndex":4,"maxResults":2,"links":{"self":"self","next":"next"}}`)
+	})
+
+	opt := &EmoticonsListOptions{Type: EmoticonTypeGroup}
+	opt.StartIndex = 4
+	opt.MaxResults = 2
+	emoticons, resp, err := suite.client.Emoticons.ListEmoticons(context.Background(), opt)
+	assert.Nil(err)
+	assert.Equal("next", resp.Links.Next)
+
+	assert.Len(emoticons, 2)
+	assert.Equal("shipit", emoticons[0].Shortcut)
+	assert.Equal("https://e.co/shipit.png", emoticons[0].Url)
+	assert.Equal("https://api.hipchat.com/v2/emoticon/1", emoticons[0].Links.Self)
+	assert.Equal(&Emoticon{Id: 2, Shortcut: "yey", Url: "https://e.co/yey.png"}, emoticons[1])
+
+	_, _, err = suite.client.Emoticons.ListEmoticons(context.Background(), &EmoticonsListOptions{Type: "custom"})
+	assert.EqualError(err, invalidEmoticonType.Error())
+}
+
+func (suite *HipChatClientTestSuite) TestEmoticonsService_GetEmoticon() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(getEmoticonRoute, "shipit")
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodGet)
+		fmt.Fprint(w, `{"id":1,"shortcut":"shipit","url":"https://e.co/shipit.png","width":30,"height":25,
+			"audio_path":"https://e.co/shipit.mp3","type":"group","creator":{"id":3,"name":"Theo"}}`)
+	})
+
+	for _, shortcut := range []string{"shipit", "(shipit)"} {
+		emoticon, _, err := suite.client.Emoticons.GetEmoticon(context.Background(), shortcut)
+		assert.Nil(err)
+
+		want := &Emoticon{
+			Id:        1,
+			Shortcut:  "shipit",
+			Url:       "https://e.co/shipit.png",
+			Width:     30,
+			Height:    25,
+			AudioPath: "https://e.co/shipit.mp3",
+			Type:      EmoticonTypeGroup,
+			Creator:   &UserListItem{Id: 3, Name: "Theo"},
+		}
+		assert.Equal(want, emoticon)
+	}
+
+	_, _, err := suite.client.Emoticons.GetEmoticon(context.Background(), "()")
+	assert.EqualError(err, emptyParam.Error())
+}
+
+func (suite *HipChatClientTestSuite) TestEmoticonsService_ListRoomEmoticons() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(listRoomEmoticonsRoute, "1")
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodGet)
+		fmt.Fprint(w, `{"items":[{"id":5,"shortcut":"opsparrot","url":"https://e.co/opsparrot.gif"}]}`)
+	})
+
+	emoticons, _, err := suite.client.Emoticons.ListRoomEmoticons(context.Background(), "1", nil)
+	assert.Nil(err)
+	assert.Equal([]*Emoticon{{Id: 5, Shortcut: "opsparrot", Url: "https://e.co/opsparrot.gif"}}, emoticons)
+
+	_, _, err = suite.client.Emoticons.ListRoomEmoticons(context.Background(), "", nil)
+	assert.EqualError(err, emptyParam.Error())
+}
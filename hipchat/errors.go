@@ -1,7 +1,181 @@
 package hipchat
 
-import "errors"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
 
 var invalidSetApiVersion = errors.New("set_api_version: apiVersion string parameter is prefixed with a forward slash (/)")
+var invalidBaseUrl = errors.New("invalid_base_url: base URL must be absolute, for example https://hipchat.example.com/")
 var emptyParam = errors.New("empty_param: required parameter is empty")
 var invalidFileUpload = errors.New("file_upload: the file to upload can't be a directory")
+var invalidPhotoSize = errors.New("user_photo: size must be one of small, big")
+var invalidPhotoMediaType = errors.New("user_photo: media type must be one of image/png, image/jpeg, image/gif")
+var invalidEmoticonType = errors.New("emoticon_type: type must be one of all, group, global")
+var invalidPage = errors.New("page_iterator: the page fetcher must return a slice")
+var invalidCardStyle = errors.New("invalid_card: style must be one of file, image, application, link, media")
+var invalidWebhookEvent = errors.New("invalid_webhook: event must be one of room_message, room_notification, room_enter, room_exit, room_topic_change, room_archived, room_deleted, room_file_upload, pattern, authentication")
+var invalidWebhookAuthentication = errors.New("invalid_webhook: authentication must be one of jwt, none")
+var invalidRateLimit = errors.New("rate_limit: limit and interval must be positive")
+
+func missingCardField(style string, field string) error {
+	return fmt.Errorf("invalid_card: %v card is missing required field %v", style, field)
+}
+
+func unknownOperation(name operationName) error {
+	return fmt.Errorf("unknown_operation: %v has no entry in the scope table", name)
+}
+
+func missingWebhookField(event string, field string) error {
+	return fmt.Errorf("invalid_webhook: %v webhook is missing required field %v", event, field)
+}
+
+// ErrorResponse reports an error caused by an API request.
+// HipChat API docs: https://developer.atlassian.com/server/hipchat/hipchat-rest-api-response-codes
+type ErrorResponse struct {
+	// HTTP response that caused this error
+	Response *http.Response `json:"-"`
+
+	// The HTTP status code of the error.
+	Code int `json:"code"`
+
+	// A human readable description of the error.
+	Message string `json:"message"`
+
+	// The type of the error, for example 'Bad Request' or 'Unauthorized'.
+	Type string `json:"type"`
+
+	// The raw response body.
+	Body []byte `json:"-"`
+}
+
+func (r *ErrorResponse) Error() string {
+	if r.Response == nil || r.Response.Request == nil {
+		return fmt.Sprintf("%d %v", r.Code, r.Message)
+	}
+
+	return fmt.Sprintf("%v %v: %d %v",
+		r.Response.Request.Method, r.Response.Request.URL, r.Code, r.Message)
+}
+
+// newErrorResponse creates an ErrorResponse from the response and its body.
+func newErrorResponse(r *http.Response, body []byte) *ErrorResponse {
+	var v struct {
+		Error *ErrorResponse `json:"error"`
+	}
+	json.Unmarshal(body, &v)
+
+	e := v.Error
+	if e == nil {
+		e = &ErrorResponse{}
+	}
+
+	e.Response = r
+	e.Body = body
+	if e.Code == 0 {
+		e.Code = r.StatusCode
+	}
+	if e.Message == "" {
+		e.Message = strings.TrimSpace(string(body))
+	}
+	if e.Type == "" {
+		e.Type = http.StatusText(r.StatusCode)
+	}
+
+	return e
+}
+
+// FileTooLargeError occurs when a file to share exceeds the size limit of
+// HipChat. It is returned before, or while, the file is uploaded.
+type FileTooLargeError struct {
+	// The size of the file. When the size isn't known upfront, it's the
+	// number of bytes read before the limit was exceeded.
+	Size int64
+
+	// The maximum size of a file.
+	Limit int64
+}
+
+func (e *FileTooLargeError) Error() string {
+	return fmt.Sprintf("file_upload: file of %d bytes exceeds the limit of %d bytes", e.Size, e.Limit)
+}
+
+// InsufficientScopeError occurs when calling an operation the token of the
+// client can't perform, according to the scopes set with Client.SetTokenScopes.
+// The request is not sent.
+type InsufficientScopeError struct {
+	// The name of the operation, for example 'Rooms.CreateRoom'.
+	Operation string
+
+	// The scopes allowing the operation.
+	Required []string
+
+	// The scopes granted to the token.
+	Granted []string
+}
+
+func (e *InsufficientScopeError) Error() string {
+	return fmt.Sprintf("insufficient_scope: %v requires one of %v, token has %v",
+		e.Operation, strings.Join(e.Required, ", "), strings.Join(e.Granted, ", "))
+}
+
+// RateLimitError occurs when HipChat returns 429 Too Many Requests response.
+// HipChat API docs: https://developer.atlassian.com/server/hipchat/hipchat-rest-api-rate-limits
+type RateLimitError struct {
+	// Rate specifies last known rate limit for the client
+	Rate Rate
+
+	// HTTP response that caused this error
+	Response *http.Response
+
+	// A human readable description of the error.
+	Message string
+}
+
+func (r *RateLimitError) Error() string {
+	if r.Response == nil || r.Response.Request == nil {
+		return fmt.Sprintf("%d %v; rate reset in %v",
+			http.StatusTooManyRequests, r.Message, time.Until(r.Rate.Reset))
+	}
+
+	return fmt.Sprintf("%v %v: %d %v; rate reset in %v",
+		r.Response.Request.Method, r.Response.Request.URL, r.Response.StatusCode, r.Message,
+		time.Until(r.Rate.Reset))
+}
+
+// IsNotFound reports whether err is an API error with a 404 status code.
+func IsNotFound(err error) bool {
+	return hasStatusCode(err, http.StatusNotFound)
+}
+
+// IsUnauthorized reports whether err is an API error with a 401 status code.
+func IsUnauthorized(err error) bool {
+	return hasStatusCode(err, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether err is an API error with a 403 status code.
+func IsForbidden(err error) bool {
+	return hasStatusCode(err, http.StatusForbidden)
+}
+
+// IsRateLimited reports whether err is a RateLimitError or an API error
+// with a 429 status code.
+func IsRateLimited(err error) bool {
+	if _, ok := err.(*RateLimitError); ok {
+		return true
+	}
+
+	return hasStatusCode(err, http.StatusTooManyRequests)
+}
+
+func hasStatusCode(err error, code int) bool {
+	if e, ok := err.(*ErrorResponse); ok {
+		return e.Code == code
+	}
+
+	return false
+}
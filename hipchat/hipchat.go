@@ -10,10 +10,17 @@ import (
 	"io"
 	"io/ioutil"
 	"log"
+	"mime"
 	"net/http"
 	"net/textproto"
 	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // A Client communicates with the HipChat API.
@@ -25,7 +32,26 @@ type Client struct {
 	common     service
 	apiVersion string
 
-	Rooms *RoomsService
+	rateMu sync.Mutex
+	rate   Rate // Rate limit for the client as determined by the most recent API call.
+
+	scopesMu sync.RWMutex
+	scopes   []string // Scopes granted to the token of the client, if known.
+
+	// Optional client side rate limiter. Requests wait for it before being sent.
+	RateLimiter *RateLimiter
+
+	// Optional policy for retrying rate limited and failed requests.
+	RetryPolicy *RetryPolicy
+
+	// Optional callback reporting the progress of file uploads.
+	UploadProgress ProgressFunc
+
+	Rooms        *RoomsService
+	Users        *UsersService
+	Emoticons    *EmoticonsService
+	OAuth        *OAuthService
+	Capabilities *CapabilitiesService
 }
 
 type service struct {
@@ -56,16 +82,56 @@ func NewClient(httpClient *http.Client) *Client {
 
 	// Services
 	c.Rooms = (*RoomsService)(&c.common)
+	c.Users = (*UsersService)(&c.common)
+	c.Emoticons = (*EmoticonsService)(&c.common)
+	c.OAuth = (*OAuthService)(&c.common)
+	c.Capabilities = (*CapabilitiesService)(&c.common)
 
 	return c
 }
 
+// apiVersionSegment matches API versions in the path of base URLs.
+var apiVersionSegment = regexp.MustCompile(`^v[0-9]+$`)
+
+// NewEnterpriseClient returns a new HipChat API client for a self-hosted
+// HipChat Server or Data Center instance. baseUrl is the root of the instance,
+// for example https://hipchat.example.com/ or https://example.com/hipchat/,
+// optionally followed by the API version.
+func NewEnterpriseClient(baseUrl string, httpClient *http.Client) (*Client, error) {
+	u, err := url.Parse(baseUrl)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, invalidBaseUrl
+	}
+
+	// The API version is appended to the path of the instance
+	p := strings.TrimSuffix(u.Path, "/")
+	if i := strings.LastIndex(p, "/"); apiVersionSegment.MatchString(p[i+1:]) {
+		p = p[:i]
+	}
+	u.Path = p + "/" + apiVersion2
+	u.RawPath = ""
+
+	c := NewClient(httpClient)
+	c.BaseUrl = u
+
+	return c, nil
+}
+
 // NewRequest creates an API request. A relative URL can be provided in urlStr,
 // in which case it is resolved relative to the BaseURL of the Client.
 // Relative URLs should always be specified without a preceding slash. If
 // specified, the value pointed to by body is JSON encoded and included as the
 // request body.
 func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Request, error) {
+	return c.NewRequestWithContext(context.Background(), method, urlStr, body)
+}
+
+// NewRequestWithContext is like NewRequest but binds ctx to the returned request.
+// Do keeps it when called with context.Background().
+func (c *Client) NewRequestWithContext(ctx context.Context, method, urlStr string, body interface{}) (*http.Request, error) {
 	ref, err := c.BaseUrl.Parse(c.apiVersion + "/" + urlStr)
 	if err != nil {
 		return nil, err
@@ -88,6 +154,7 @@ func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Requ
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
 
 	if body != nil {
 		req.Header.Set("Content-Type", contentTypeApplicationJson)
@@ -100,8 +167,45 @@ func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Requ
 	return req, nil
 }
 
-// NewUploadRequest creates an upload request.
+// newFormRequest creates a POST request with form as its URL encoded body.
+func (c *Client) newFormRequest(urlStr string, form url.Values) (*http.Request, error) {
+	ref, err := c.BaseUrl.Parse(c.apiVersion + "/" + urlStr)
+	if err != nil {
+		return nil, err
+	}
+
+	u := c.BaseUrl.ResolveReference(ref)
+	req, err := http.NewRequest(http.MethodPost, u.String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", contentTypeForm)
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	return req, nil
+}
+
+// NewUploadRequest creates an upload request. The contents of reader are
+// streamed when the request is sent. size is the number of bytes that will be
+// read from reader, or zero if unknown, in which case the request is sent
+// chunked.
 func (c *Client) NewUploadRequest(
+	urlStr string,
+	reader io.Reader,
+	size int64,
+	mediaType string,
+	body interface{},
+	fileName string) (*http.Request, error) {
+	return c.NewUploadRequestWithContext(context.Background(), urlStr, reader, size, mediaType, body, fileName)
+}
+
+// NewUploadRequestWithContext is like NewUploadRequest but binds ctx to the returned request.
+// Do keeps it when called with context.Background().
+func (c *Client) NewUploadRequestWithContext(
+	ctx context.Context,
 	urlStr string,
 	reader io.Reader,
 	size int64,
@@ -150,25 +254,48 @@ func (c *Client) NewUploadRequest(
 	var header = make(textproto.MIMEHeader)
 	header.Set("Content-Type", mediaType)
 	header.Set("Content-Disposition", fmt.Sprintf(contentDispositionFile, fileName))
-	nextPart, err := w.CreatePart("", header)
+	_, err = w.CreatePart("", header)
 	if err != nil {
 		return nil, err
 	}
 
-	upload, err := ioutil.ReadAll(reader)
-	if err != nil {
+	// The body is streamed from the reader, so only the multipart headers
+	// and the closing boundary are buffered.
+	head := make([]byte, wBuf.Len())
+	copy(head, wBuf.Bytes())
+	wBuf.Reset()
+	if err := w.Close(); err != nil {
 		return nil, err
 	}
+	tail := wBuf.Bytes()
 
-	nextPart.Write(upload)
-	if err := w.Close(); err != nil {
-		return nil, err
+	newBody := func(r io.Reader) io.ReadCloser {
+		return ioutil.NopCloser(io.MultiReader(
+			bytes.NewReader(head),
+			&progressReader{r: r, total: size, progress: c.UploadProgress},
+			bytes.NewReader(tail)))
 	}
 
-	req, err := http.NewRequest("POST", ref.String(), bytes.NewReader(wBuf.Bytes()))
+	req, err := http.NewRequest("POST", ref.String(), newBody(reader))
 	if err != nil {
 		return nil, err
 	}
+	if size > 0 {
+		req.ContentLength = int64(len(head)) + size + int64(len(tail))
+	}
+
+	// Seekable readers can be rewound, which lets the request be retried.
+	if seeker, ok := reader.(io.Seeker); ok {
+		if offset, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			req.GetBody = func() (io.ReadCloser, error) {
+				if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+					return nil, err
+				}
+				return newBody(reader), nil
+			}
+		}
+	}
+	req = req.WithContext(ctx)
 
 	req.Header.Set("Content-Type", w.FormDataContentType())
 	req.Header.Set("User-Agent", c.UserAgent)
@@ -176,6 +303,169 @@ func (c *Client) NewUploadRequest(
 	return req, nil
 }
 
+// newShareFileRequest creates an upload request sharing file along with an
+// optional message.
+func (c *Client) newShareFileRequest(urlStr string, file *os.File, message string) (*http.Request, error) {
+	if file == nil {
+		return nil, emptyParam
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if stat.IsDir() {
+		return nil, invalidFileUpload
+	}
+
+	return c.newShareReaderRequest(urlStr, file, baseFileName(file.Name()), "", message)
+}
+
+// newShareReaderRequest creates an upload request sharing the contents of
+// reader as fileName along with an optional message.
+//
+// When mediaType is empty it's guessed from the extension of fileName, or
+// sniffed from the first 512 bytes of the contents if the extension is
+// unknown. Files larger than MaxFileSize are rejected with a FileTooLargeError.
+func (c *Client) newShareReaderRequest(
+	urlStr string,
+	reader io.Reader,
+	fileName string,
+	mediaType string,
+	message string) (*http.Request, error) {
+	if reader == nil || fileName == "" {
+		return nil, emptyParam
+	}
+
+	var m sendMessageBody
+	if message != "" {
+		m = sendMessageBody{message}
+	}
+
+	size, err := readerSize(reader)
+	if err != nil {
+		return nil, err
+	}
+	if size > MaxFileSize {
+		return nil, &FileTooLargeError{Size: size, Limit: MaxFileSize}
+	}
+
+	if mediaType == "" {
+		mediaType = mime.TypeByExtension(filepath.Ext(fileName))
+	}
+	if mediaType == "" {
+		if mediaType, reader, err = sniffContentType(reader); err != nil {
+			return nil, err
+		}
+	}
+
+	// The size is checked as the contents are read when it's not known
+	if size < 0 {
+		reader = &fileSizeLimitReader{r: reader, limit: MaxFileSize}
+		size = 0
+	}
+
+	return c.NewUploadRequest(urlStr, reader, size, mediaType, m, fileName)
+}
+
+// readerSize returns the number of bytes left to read from r, or -1 if it
+// can't be known without reading r.
+func readerSize(r io.Reader) (int64, error) {
+	switch v := r.(type) {
+	case interface {
+		Len() int
+	}:
+		return int64(v.Len()), nil
+	case io.Seeker:
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			// Not every seeker can seek, pipes for example
+			return -1, nil
+		}
+		end, err := v.Seek(0, io.SeekEnd)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := v.Seek(offset, io.SeekStart); err != nil {
+			return 0, err
+		}
+
+		return end - offset, nil
+	}
+
+	return -1, nil
+}
+
+// sniffContentType detects the media type of the contents of r. It returns a
+// reader yielding the full contents, as the first bytes have to be read.
+func sniffContentType(r io.Reader) (string, io.Reader, error) {
+	var offset int64 = -1
+	seeker, ok := r.(io.Seeker)
+	if ok {
+		if n, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			offset = n
+		}
+	}
+
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", nil, err
+	}
+	buf = buf[:n]
+	mediaType := http.DetectContentType(buf)
+
+	// Seeking back keeps the reader seekable, so the upload can be retried
+	if offset >= 0 {
+		if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+			return "", nil, err
+		}
+		return mediaType, r, nil
+	}
+
+	return mediaType, io.MultiReader(bytes.NewReader(buf), r), nil
+}
+
+// fileSizeLimitReader fails with a FileTooLargeError once more than limit
+// bytes are read.
+type fileSizeLimitReader struct {
+	r     io.Reader
+	n     int64
+	limit int64
+}
+
+func (l *fileSizeLimitReader) Read(b []byte) (int, error) {
+	n, err := l.r.Read(b)
+	l.n += int64(n)
+	if l.n > l.limit {
+		return n, &FileTooLargeError{Size: l.n, Limit: l.limit}
+	}
+
+	return n, err
+}
+
+// ProgressFunc reports the number of bytes of a file sent so far, out of total.
+// total is zero when the size of the file is unknown.
+type ProgressFunc func(sent int64, total int64)
+
+// progressReader reports the number of bytes read through it.
+type progressReader struct {
+	r        io.Reader
+	sent     int64
+	total    int64
+	progress ProgressFunc
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 && p.progress != nil {
+		p.sent += int64(n)
+		p.progress(p.sent, p.total)
+	}
+
+	return n, err
+}
+
 // PaginatedResponse is a HipChat API response. This wraps the standard http.Response
 // returned from HipChat and provides convenient access to things like
 // pagination links.
@@ -184,6 +474,21 @@ type PaginatedResponse struct {
 	MaxResults int
 	StartIndex int
 	Links      *PaginationLinks
+
+	// Rate limit information parsed from the X-Ratelimit-* response headers.
+	Rate Rate
+}
+
+// Rate represents the rate limit for the current client.
+type Rate struct {
+	// The number of requests per time window the client is limited to.
+	Limit int
+
+	// The number of remaining requests the client can make in the current window.
+	Remaining int
+
+	// The time at which the current rate limit window resets.
+	Reset time.Time
 }
 
 // PaginationLinks consists of a list of links for the current, next and previous pagination
@@ -216,9 +521,37 @@ func newPaginatedResponse(r *http.Response) (*PaginatedResponse, []byte) {
 	response.StartIndex = v.StartIndex
 
 	response.Links = (*PaginationLinks)(v.Links)
+	response.Rate = parseRate(r)
 	return response, rs
 }
 
+// parseRate parses the rate related headers.
+func parseRate(r *http.Response) Rate {
+	var rate Rate
+	if limit := r.Header.Get(headerRateLimit); limit != "" {
+		rate.Limit, _ = strconv.Atoi(limit)
+	}
+	if remaining := r.Header.Get(headerRateRemaining); remaining != "" {
+		rate.Remaining, _ = strconv.Atoi(remaining)
+	}
+	if reset := r.Header.Get(headerRateReset); reset != "" {
+		if v, _ := strconv.ParseInt(reset, 10, 64); v != 0 {
+			rate.Reset = time.Unix(v, 0)
+		}
+	}
+
+	return rate
+}
+
+// Rate returns the rate limit for the client as determined by the most recent
+// API call. The zero value is returned if no call has reported one yet.
+func (c *Client) Rate() Rate {
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+
+	return c.rate
+}
+
 // Convenient shorthand for GET requests
 func (c *Client) Get(urlStr string) (*http.Request, error) {
 	return c.NewRequest(http.MethodGet, urlStr, nil)
@@ -245,9 +578,41 @@ func (c *Client) Delete(urlStr string) (*http.Request, error) {
 // interface, the raw response body will be written to v, without attempting to
 // first decode it.
 
-// The provided ctx must be non-nil. If it is canceled or times out,
-// ctx.Err() will be returned.
+// The provided ctx must be non-nil and is bound to the request, so canceling
+// it aborts the request in flight. If it is canceled or times out,
+// ctx.Err() will be returned. When ctx is context.Background(), the context
+// the request was created with is used instead.
+//
+// If the client has a RateLimiter, Do waits for it before sending the request.
+// If it has a RetryPolicy, requests failing with 429 Too Many Requests, and
+// idempotent requests failing with a 5xx status code, are retried with
+// exponential backoff.
 func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*PaginatedResponse, error) {
+	if ctx == context.Background() {
+		ctx = req.Context()
+	}
+	req = req.WithContext(ctx)
+
+	for attempt := 0; ; attempt++ {
+		if c.RateLimiter != nil {
+			if err := c.RateLimiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.do(ctx, req, v)
+		if !c.RetryPolicy.shouldRetry(req, attempt, err) || !rewindBody(req) {
+			return resp, err
+		}
+
+		if err := sleep(ctx, c.RetryPolicy.backoff(attempt, err)); err != nil {
+			return resp, err
+		}
+	}
+}
+
+// do sends a single API request.
+func (c *Client) do(ctx context.Context, req *http.Request, v interface{}) (*PaginatedResponse, error) {
 	resp, err := c.client.Do(req)
 	if err != nil {
 		// If we got an error, and the context has been canceled,
@@ -258,6 +623,14 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Pag
 		default:
 		}
 
+		// Errors returned while reading the request body are wrapped, but
+		// the size limit of uploads is better reported as is.
+		if e, ok := err.(*url.Error); ok {
+			if e, ok := e.Err.(*FileTooLargeError); ok {
+				return nil, e
+			}
+		}
+
 		return nil, err
 	}
 
@@ -270,9 +643,25 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Pag
 		}
 	}()
 
+	rate := parseRate(resp)
+	if rate.Limit > 0 {
+		c.rateMu.Lock()
+		c.rate = rate
+		c.rateMu.Unlock()
+	}
+
 	if resp.StatusCode >= 300 {
 		rs, _ := ioutil.ReadAll(resp.Body)
-		return &PaginatedResponse{Response: resp}, errors.New(string(rs))
+		response := &PaginatedResponse{Response: resp, Rate: rate}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return response, &RateLimitError{
+				Rate:     rate,
+				Response: resp,
+				Message:  newErrorResponse(resp, rs).Message,
+			}
+		}
+
+		return response, newErrorResponse(resp, rs)
 	}
 
 	// Populate pagination params
@@ -302,14 +691,27 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Pag
 }
 
 // Sets the HipChat API version. This defaults to v2
+//
+// The host and path prefix of the base URL are kept, so the version can be set
+// on enterprise clients as well. The last segment of the path is replaced when
+// it's a version, such as v2, and the version is appended otherwise.
 func (c *Client) SetApiVersion(apiVersion string) error {
 	if strings.HasPrefix(apiVersion, "/") {
 		return invalidSetApiVersion
 	}
 
-	baseUrl, _ := url.Parse(string(defaultBaseUrl + apiVersion))
+	// Only the version, the last segment of the base URL, is replaced. Base
+	// URLs without a version get one appended
+	baseUrl := *c.BaseUrl
+	p := strings.TrimSuffix(baseUrl.Path, "/")
+	if i := strings.LastIndex(p, "/"); apiVersionSegment.MatchString(p[i+1:]) {
+		p = p[:i]
+	}
+	baseUrl.Path = p + "/" + apiVersion
+	baseUrl.RawPath = ""
+
 	c.apiVersion = apiVersion
-	c.BaseUrl = baseUrl
+	c.BaseUrl = &baseUrl
 
 	return nil
 }
@@ -7,11 +7,14 @@ import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"runtime"
 	"testing"
+	"time"
 )
 
 type HipChatClientTestSuite struct {
@@ -49,20 +52,25 @@ func (suite *HipChatClientTestSuite) TestNewClient() {
 	assert := assert.New(suite.T())
 
 	assert.NotNil(suite.client.Rooms)
+	assert.NotNil(suite.client.Users)
+	assert.NotNil(suite.client.Emoticons)
+	assert.NotNil(suite.client.OAuth)
+	assert.NotNil(suite.client.Capabilities)
 }
 
 func (suite *HipChatClientTestSuite) TestClient_SetApiVersion() {
 	assert := assert.New(suite.T())
 	expectedBaseUrl, _ := url.Parse("https://api.hipchat.com/v3")
+	client := NewClient(nil)
 
-	err := suite.client.SetApiVersion("v3")
+	err := client.SetApiVersion("v3")
 
 	assert.Nil(err)
-	assert.Equal(suite.client.BaseUrl, expectedBaseUrl)
+	assert.Equal(client.BaseUrl, expectedBaseUrl)
 
-	err = suite.client.SetApiVersion("/v3")
+	err = client.SetApiVersion("/v3")
 	assert.NotNil(err)
-	assert.Equal(suite.client.BaseUrl, expectedBaseUrl)
+	assert.Equal(client.BaseUrl, expectedBaseUrl)
 
 }
 
@@ -108,6 +116,117 @@ func (suite *HipChatClientTestSuite) TestClient_TestNewUploadRequest() {
 	assert.Equal(http.MethodPost, req.Method)
 }
 
+func (suite *HipChatClientTestSuite) TestClient_TestNewUploadRequestContentLength() {
+	assert := assert.New(suite.T())
+
+	var progress []int64
+	suite.client.UploadProgress = func(sent int64, total int64) {
+		assert.Equal(int64(11), total)
+		progress = append(progress, sent)
+	}
+
+	f := bytes.NewReader([]byte("Hello World"))
+	req, err := suite.client.NewUploadRequest("bar", f, int64(f.Len()), "text/plain", sendMessageBody{"message"}, "hello.txt")
+	assert.Nil(err)
+
+	body, _ := ioutil.ReadAll(req.Body)
+	assert.Equal(int64(len(body)), req.ContentLength)
+	assert.Contains(string(body), "Hello World")
+	assert.Equal(int64(11), progress[len(progress)-1])
+
+	// The body can be rebuilt from seekable readers
+	body2, _ := req.GetBody()
+	rewound, _ := ioutil.ReadAll(body2)
+	assert.Equal(body, rewound)
+}
+
+// zeroReader is an endless source of zero bytes.
+type zeroReader struct{}
+
+func (zeroReader) Read(b []byte) (int, error) {
+	for i := range b {
+		b[i] = 0
+	}
+	return len(b), nil
+}
+
+func (suite *HipChatClientTestSuite) TestClient_TestNewUploadRequestStreaming() {
+	if testing.Short() {
+		suite.T().Skip("skipping large upload in short mode")
+	}
+	assert := assert.New(suite.T())
+	const size = 256 << 20
+
+	var received int64
+	suite.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.Copy(ioutil.Discard, r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	var sent int64
+	suite.client.UploadProgress = func(n int64, total int64) {
+		sent = n
+	}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	reader := io.LimitReader(zeroReader{}, size)
+	req, err := suite.client.NewUploadRequest(".", reader, size, "application/octet-stream", sendMessageBody{"build"}, "build.log")
+	assert.Nil(err)
+
+	resp, err := suite.client.Do(context.Background(), req, nil)
+	assert.Nil(err)
+	assert.Equal(http.StatusNoContent, resp.StatusCode)
+
+	runtime.ReadMemStats(&after)
+
+	// Nothing close to the size of the upload should have been allocated
+	assert.True(after.TotalAlloc-before.TotalAlloc < 32<<20,
+		fmt.Sprintf("allocated %d bytes", after.TotalAlloc-before.TotalAlloc))
+	assert.Equal(int64(size), sent)
+	assert.Equal(req.ContentLength, received)
+}
+
+func (suite *HipChatClientTestSuite) TestClient_TestNewRequestWithContext() {
+	assert := assert.New(suite.T())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, err := suite.client.NewRequestWithContext(ctx, "GET", "bar", nil)
+	assert.Nil(err)
+	assert.Equal(ctx, req.Context())
+
+	f := bytes.NewReader([]byte("Hello World"))
+	req, err = suite.client.NewUploadRequestWithContext(ctx, "bar", f, 0, "text/html", nil, "upload.png")
+	assert.Nil(err)
+	assert.Equal(ctx, req.Context())
+
+	req, _ = suite.client.NewRequest("GET", "bar", nil)
+	assert.Equal(context.Background(), req.Context())
+}
+
+func (suite *HipChatClientTestSuite) TestDo_requestContext() {
+	assert := assert.New(suite.T())
+	suite.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The context of the request is kept when Do is given none
+	req, _ := suite.client.NewRequestWithContext(ctx, "GET", ".", nil)
+	_, err := suite.client.Do(context.Background(), req, nil)
+	assert.Equal(context.Canceled, err)
+
+	// The context given to Do takes precedence otherwise
+	req, _ = suite.client.NewRequestWithContext(ctx, "GET", ".", nil)
+	_, err = suite.client.Do(context.TODO(), req, nil)
+	assert.Nil(err)
+}
+
 func (suite *HipChatClientTestSuite) TestNewRequest_invalidJSON() {
 	assert := assert.New(suite.T())
 	type T struct {
@@ -181,3 +300,227 @@ func (suite *HipChatClientTestSuite) TestDo_httpError() {
 
 	assert.Equal(400, resp.StatusCode)
 }
+
+func (suite *HipChatClientTestSuite) TestDo_contextCanceled() {
+	assert := assert.New(suite.T())
+
+	suite.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		// A slow handler that only returns once the client goes away
+		ioutil.ReadAll(r.Body)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+
+	f := bytes.NewReader([]byte("Hello World"))
+	upload, _ := suite.client.NewUploadRequest(".", f, int64(f.Len()), "text/plain", sendMessageBody{"message"}, "hello.txt")
+	get, _ := suite.client.Get(".")
+
+	for _, req := range []*http.Request{get, upload} {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		start := time.Now()
+		_, err := suite.client.Do(ctx, req, nil)
+		cancel()
+
+		assert.Equal(context.DeadlineExceeded, err)
+		assert.True(time.Since(start) < 2*time.Second)
+	}
+}
+
+func (suite *HipChatClientTestSuite) TestDo_errorResponse() {
+	assert := assert.New(suite.T())
+	body := `{"error":{"code":404,"message":"Room not found","type":"Not Found"}}`
+
+	suite.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, body)
+	})
+
+	req, _ := suite.client.Get("room/1")
+	resp, err := suite.client.Do(context.Background(), req, nil)
+	assert.Equal(http.StatusNotFound, resp.StatusCode)
+
+	e, ok := err.(*ErrorResponse)
+	if !ok {
+		assert.FailNow("expected an *ErrorResponse")
+	}
+	assert.Equal(404, e.Code)
+	assert.Equal("Room not found", e.Message)
+	assert.Equal("Not Found", e.Type)
+	assert.Equal(body, string(e.Body))
+	assert.Equal(resp.Response, e.Response)
+	assert.Equal(fmt.Sprintf("GET %v/v2/room/1: 404 Room not found", suite.server.URL), e.Error())
+
+	assert.True(IsNotFound(err))
+	assert.False(IsUnauthorized(err))
+	assert.False(IsRateLimited(err))
+}
+
+func (suite *HipChatClientTestSuite) TestDo_errorResponsePlainBody() {
+	assert := assert.New(suite.T())
+
+	suite.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	})
+
+	req, _ := suite.client.Get(".")
+	_, err := suite.client.Do(context.Background(), req, nil)
+
+	e, ok := err.(*ErrorResponse)
+	if !ok {
+		assert.FailNow("expected an *ErrorResponse")
+	}
+	assert.Equal(http.StatusUnauthorized, e.Code)
+	assert.Equal("Unauthorized", e.Message)
+	assert.Equal("Unauthorized", e.Type)
+	assert.True(IsUnauthorized(err))
+	assert.False(IsForbidden(err))
+}
+
+func (suite *HipChatClientTestSuite) TestDo_rateLimit() {
+	assert := assert.New(suite.T())
+	reset := time.Now().Add(time.Minute).Truncate(time.Second)
+
+	suite.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerRateLimit, "500")
+		w.Header().Set(headerRateRemaining, "499")
+		w.Header().Set(headerRateReset, fmt.Sprint(reset.Unix()))
+		fmt.Fprint(w, `{}`)
+	})
+
+	assert.Equal(Rate{}, suite.client.Rate())
+
+	req, _ := suite.client.Get(".")
+	resp, err := suite.client.Do(context.Background(), req, nil)
+	assert.Nil(err)
+
+	want := Rate{Limit: 500, Remaining: 499, Reset: reset}
+	assert.Equal(want.Limit, resp.Rate.Limit)
+	assert.Equal(want.Remaining, resp.Rate.Remaining)
+	assert.True(want.Reset.Equal(resp.Rate.Reset))
+	assert.Equal(resp.Rate, suite.client.Rate())
+}
+
+func (suite *HipChatClientTestSuite) TestDo_rateLimitError() {
+	assert := assert.New(suite.T())
+	reset := time.Now().Add(time.Minute).Truncate(time.Second)
+
+	suite.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerRateLimit, "500")
+		w.Header().Set(headerRateRemaining, "0")
+		w.Header().Set(headerRateReset, fmt.Sprint(reset.Unix()))
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"error":{"code":429,"message":"Rate limit exceeded","type":"Too Many Requests"}}`)
+	})
+
+	req, _ := suite.client.Get(".")
+	resp, err := suite.client.Do(context.Background(), req, nil)
+	assert.Equal(http.StatusTooManyRequests, resp.StatusCode)
+
+	e, ok := err.(*RateLimitError)
+	if !ok {
+		assert.FailNow("expected a *RateLimitError")
+	}
+	assert.Equal("Rate limit exceeded", e.Message)
+	assert.Equal(0, e.Rate.Remaining)
+	assert.True(reset.Equal(e.Rate.Reset))
+	assert.True(IsRateLimited(err))
+	assert.Equal(0, suite.client.Rate().Remaining)
+}
+
+func (suite *HipChatClientTestSuite) TestErrorResponse_helpers() {
+	assert := assert.New(suite.T())
+	testCases := []struct {
+		name string
+		code int
+		is   func(error) bool
+	}{
+		{"TestIsNotFound", http.StatusNotFound, IsNotFound},
+		{"TestIsUnauthorized", http.StatusUnauthorized, IsUnauthorized},
+		{"TestIsForbidden", http.StatusForbidden, IsForbidden},
+		{"TestIsRateLimited", http.StatusTooManyRequests, IsRateLimited},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			assert.True(tc.is(&ErrorResponse{Code: tc.code}))
+			assert.False(tc.is(&ErrorResponse{Code: http.StatusInternalServerError}))
+			assert.False(tc.is(emptyParam))
+			assert.False(tc.is(nil))
+		})
+	}
+}
+
+func (suite *HipChatClientTestSuite) TestNewEnterpriseClient() {
+	testCases := []struct {
+		name    string
+		baseUrl string
+		want    string
+	}{
+		{"TestHost", "https://hipchat.example.com", "https://hipchat.example.com/v2"},
+		{"TestHostWithSlash", "https://hipchat.example.com/", "https://hipchat.example.com/v2"},
+		{"TestSubpath", "https://example.com/tools/hipchat", "https://example.com/tools/hipchat/v2"},
+		{"TestSubpathWithSlash", "https://example.com/tools/hipchat/", "https://example.com/tools/hipchat/v2"},
+		{"TestSubpathWithVersion", "https://example.com/hipchat/v2/", "https://example.com/hipchat/v2"},
+		{"TestPort", "http://10.0.0.1:8080/hipchat", "http://10.0.0.1:8080/hipchat/v2"},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			c, err := NewEnterpriseClient(tc.baseUrl, nil)
+			assert.Nil(err)
+			assert.Equal(tc.want, c.BaseUrl.String())
+			assert.NotNil(c.Rooms)
+
+			req, err := c.Get("room")
+			assert.Nil(err)
+			assert.Equal(tc.want+"/room", req.URL.String())
+		})
+	}
+
+	assert := assert.New(suite.T())
+	for _, baseUrl := range []string{"hipchat.example.com", "/hipchat", "://"} {
+		_, err := NewEnterpriseClient(baseUrl, nil)
+		assert.NotNil(err, baseUrl)
+	}
+}
+
+func (suite *HipChatClientTestSuite) TestClient_SetApiVersionSubpath() {
+	assert := assert.New(suite.T())
+
+	c, _ := NewEnterpriseClient("https://example.com/tools/hipchat/", nil)
+	assert.Nil(c.SetApiVersion("v3"))
+	assert.Equal("https://example.com/tools/hipchat/v3", c.BaseUrl.String())
+
+	req, _ := c.Get("room")
+	assert.Equal("https://example.com/tools/hipchat/v3/room", req.URL.String())
+
+	// Custom base URLs are kept as well
+	url, _ := url.Parse(suite.server.URL)
+	suite.client.BaseUrl = url
+	assert.Nil(suite.client.SetApiVersion("v3"))
+	assert.Equal(suite.server.URL+"/v3", suite.client.BaseUrl.String())
+}
+
+func (suite *HipChatClientTestSuite) TestClient_SetApiVersionWithoutVersion() {
+	testCases := []struct {
+		name    string
+		baseUrl string
+		want    string
+	}{
+		{"TestSubpath", "https://example.com/hipchat", "https://example.com/hipchat/v3"},
+		{"TestSubpathWithSlash", "https://example.com/hipchat/", "https://example.com/hipchat/v3"},
+		{"TestSubpathWithVersion", "https://example.com/hipchat/v2", "https://example.com/hipchat/v3"},
+		{"TestHost", "https://example.com", "https://example.com/v3"},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			c := NewClient(nil)
+			c.BaseUrl, _ = url.Parse(tc.baseUrl)
+
+			assert.Nil(c.SetApiVersion("v3"))
+			assert.Equal(tc.want, c.BaseUrl.String())
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package hipchat
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+const (
+	// History message types
+	HistoryMessageTypeMessage      = "message"
+	HistoryMessageTypeNotification = "notification"
+	HistoryMessageTypeGuestAccess  = "guest_access"
+	HistoryMessageTypeTopic        = "topic"
+	HistoryMessageTypeArchiveRoom  = "archive-room"
+)
+
+// HistoryMessage represents a HipChat chat history item. The Type field tells
+// which kind of message it is and therefore which of the optional fields are set.
+type HistoryMessage struct {
+	// The unique identifier of the message.
+	Id string `json:"id"`
+
+	// Type of message.
+	// Valid values: message, notification, guest_access, topic, archive-room.
+	Type string `json:"type"`
+
+	// The date the message was sent in ISO-8601 format.
+	Date string `json:"date"`
+
+	// The message body.
+	Message string `json:"message"`
+
+	// The user that sent a message. Set for every type except notifications.
+	From *UserListItem `json:"-"`
+
+	// The label of the integration that sent a notification. Only set for notifications.
+	FromName string `json:"-"`
+
+	// Determines how the message is rendered. Only set for notifications.
+	// Valid values: html, text.
+	MessageFormat string `json:"message_format,omitempty"`
+
+	// Background color for the message. Only set for notifications.
+	Color string `json:"color,omitempty"`
+
+	// The users mentioned in the message.
+	Mentions []*UserListItem `json:"mentions,omitempty"`
+
+	// The file shared along with the message, if any.
+	File *MessageFile `json:"file,omitempty"`
+
+	// The card attached to a notification, if any.
+	Card *Card `json:"-"`
+}
+
+// MessageFile represents a file shared in a HipChat message
+type MessageFile struct {
+	// The name of the file.
+	Name string `json:"name"`
+
+	// The size of the file in bytes.
+	Size int64 `json:"size"`
+
+	// The URL of the file.
+	Url string `json:"url"`
+
+	// The URL of the file thumbnail, if any.
+	ThumbUrl string `json:"thumb_url,omitempty"`
+}
+
+// UnmarshalJSON decodes a history item, resolving the polymorphic "from" and
+// "card" attributes into their typed fields.
+func (m *HistoryMessage) UnmarshalJSON(data []byte) error {
+	type message HistoryMessage
+	aux := struct {
+		*message
+		From json.RawMessage `json:"from"`
+		Card json.RawMessage `json:"card"`
+	}{message: (*message)(m)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if from := bytes.TrimSpace(aux.From); len(from) > 0 && !bytes.Equal(from, []byte("null")) {
+		if from[0] == '"' {
+			if err := json.Unmarshal(from, &m.FromName); err != nil {
+				return err
+			}
+		} else {
+			m.From = new(UserListItem)
+			if err := json.Unmarshal(from, m.From); err != nil {
+				return err
+			}
+		}
+	}
+
+	if card := bytes.TrimSpace(aux.Card); len(card) > 0 && !bytes.Equal(card, []byte("null")) {
+		// Cards are returned as JSON encoded strings
+		if card[0] == '"' {
+			var s string
+			if err := json.Unmarshal(card, &s); err != nil {
+				return err
+			}
+			card = []byte(s)
+		}
+		m.Card = new(Card)
+		if err := json.Unmarshal(card, m.Card); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes a history item, writing the sender back into the
+// polymorphic "from" attribute. The card is encoded as an object, which
+// UnmarshalJSON reads as well.
+func (m HistoryMessage) MarshalJSON() ([]byte, error) {
+	type message HistoryMessage
+	aux := struct {
+		message
+		From interface{} `json:"from,omitempty"`
+		Card *Card       `json:"card,omitempty"`
+	}{message: message(m), Card: m.Card}
+
+	if m.From != nil {
+		aux.From = m.From
+	} else if m.FromName != "" {
+		aux.From = m.FromName
+	}
+
+	return json.Marshal(aux)
+}
+
+// HistoryOptions specifies the optional parameters to the
+// chat history methods.
+type HistoryOptions struct {
+	// Either the latest date to fetch history for in ISO-8601 format, or 'recent' to fetch
+	// the latest 75 messages.
+	Date string `url:"date,omitempty"`
+
+	// Your timezone. Must be a supported timezone.
+	//
+	// Defaults to 'UTC'.
+	Timezone string `url:"timezone,omitempty"`
+
+	// Reverse the output such that the oldest message is first.
+	// It is always sent, so the zero value returns the newest messages first which
+	// is what consistent paging requires.
+	Reverse bool `url:"reverse"`
+
+	// Either the earliest date to fetch history for the ISO-8601 format string,
+	// or leave blank to disable this filter.
+	EndDate string `url:"end-date,omitempty"`
+
+	// Include records about deleted messages into results (body of a message isn't returned).
+	IncludeDeleted bool `url:"include_deleted,omitempty"`
+	ListOptions
+}
+
+// RecentHistoryOptions specifies the optional parameters to the
+// latest chat history methods.
+type RecentHistoryOptions struct {
+	// Your timezone. Must be a supported timezone.
+	//
+	// Defaults to 'UTC'.
+	Timezone string `url:"timezone,omitempty"`
+
+	// The id of the message that is oldest in the set of messages to be returned.
+	// The server will not return any messages that chronologically precede this message.
+	NotBefore string `url:"not-before,omitempty"`
+
+	// Include records about deleted messages into results (body of a message isn't returned).
+	IncludeDeleted bool `url:"include_deleted,omitempty"`
+
+	// The maximum number of messages to return.
+	MaxResults int `url:"max-results,omitempty"`
+}
+
+// MessageOptions specifies the optional parameters to the
+// single message methods.
+type MessageOptions struct {
+	// The maximum number of messages to return as the context window around the message.
+	//
+	// Defaults to '75'.
+	MaxResults int `url:"max-results,omitempty"`
+
+	// Your timezone. Must be a supported timezone.
+	//
+	// Defaults to 'UTC'.
+	Timezone string `url:"timezone,omitempty"`
+
+	// Include records about deleted messages into results (body of a message isn't returned).
+	IncludeDeleted bool `url:"include_deleted,omitempty"`
+}
+
+// RoomMessageContext represents a room message along with the messages
+// surrounding it
+type RoomMessageContext struct {
+	// The requested message.
+	Message *HistoryMessage `json:"message"`
+
+	// The messages sent around the requested one, oldest first. At most
+	// MessageOptions.MaxResults of them are returned.
+	Context []*HistoryMessage `json:"items,omitempty"`
+}
+
+type historyListResponse struct {
+	Items []*HistoryMessage `json:"items,omitempty"`
+}
@@ -0,0 +1,286 @@
+package hipchat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/go-querystring/query"
+	"golang.org/x/oauth2"
+	"strings"
+	"time"
+)
+
+const (
+	// OAuth grant types
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeClientCredentials = "client_credentials"
+	GrantTypePassword          = "password"
+	GrantTypePersonal          = "personal"
+	GrantTypeRefreshToken      = "refresh_token"
+
+	oauthTokenRoute   = "oauth/token"
+	oauthSessionRoute = "oauth/token/%v"
+)
+
+// OAuthService handles communication with the OAuth related
+// methods of the HipChat API.
+type OAuthService service
+
+// TokenRequest specifies the parameters of a request for an access token.
+// Which parameters are required depends on the grant type.
+type TokenRequest struct {
+	// The type of grant request.
+	// Valid values: authorization_code, client_credentials, password, personal, refresh_token.
+	GrantType string `url:"grant_type"`
+
+	// The scopes the token should be granted.
+	Scopes []string `url:"scope,space,omitempty"`
+
+	// The user name to generate a token on behalf of. Only valid for the
+	// password and personal grants.
+	Username string `url:"username,omitempty"`
+
+	// The id of the user the token is generated for. Only valid for the
+	// personal grant.
+	UserId string `url:"user_id,omitempty"`
+
+	// The password of the user. Only valid for the password grant.
+	Password string `url:"password,omitempty"`
+
+	// The authorization code to exchange. Only valid for the
+	// authorization_code grant.
+	Code string `url:"code,omitempty"`
+
+	// The URL the authorization code was sent to. Only valid for the
+	// authorization_code grant.
+	RedirectUri string `url:"redirect_uri,omitempty"`
+
+	// The refresh token to exchange. Only valid for the refresh_token grant.
+	RefreshToken string `url:"refresh_token,omitempty"`
+
+	// The name of the public oauth client retrieving a token for.
+	ClientName string `url:"client_name,omitempty"`
+
+	// The id of the group the token is generated for.
+	GroupId int64 `url:"group_id,omitempty"`
+
+	// The credentials of the oauth client, sent with basic authentication.
+	ClientId     string `url:"-"`
+	ClientSecret string `url:"-"`
+}
+
+// Token represents a HipChat OAuth access token
+type Token struct {
+	// The generated access token.
+	AccessToken string `json:"access_token"`
+
+	// The type of the token, 'bearer'.
+	TokenType string `json:"token_type"`
+
+	// The number of seconds the token is valid for.
+	ExpiresIn int64 `json:"expires_in"`
+
+	// The time the token expires at, computed from ExpiresIn when the token is
+	// generated.
+	Expiry time.Time `json:"-"`
+
+	// The refresh token, if the grant type issues one.
+	RefreshToken string `json:"refresh_token,omitempty"`
+
+	// The scopes granted to the token.
+	Scopes []string `json:"-"`
+
+	// The id of the group the token belongs to.
+	GroupId int64 `json:"group_id"`
+
+	// The name of the group the token belongs to.
+	GroupName string `json:"group_name"`
+}
+
+// UnmarshalJSON decodes a token, splitting its space separated scopes.
+func (t *Token) UnmarshalJSON(data []byte) error {
+	type token Token
+	aux := struct {
+		*token
+		Scope string `json:"scope"`
+	}{token: (*token)(t)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	t.Scopes = strings.Fields(aux.Scope)
+
+	return nil
+}
+
+// Session represents the OAuth session of an access token
+type Session struct {
+	// The access token of the session.
+	AccessToken string `json:"access_token"`
+
+	// The time the session expires at in ISO 8601 format UTC.
+	Expires string `json:"expires"`
+
+	// The scopes granted to the session.
+	Scopes []string `json:"scopes"`
+
+	// The oauth client the session was created with.
+	Client *SessionClient `json:"client,omitempty"`
+
+	// The user owning the session, if any.
+	Owner *UserListItem `json:"owner,omitempty"`
+}
+
+// SessionClient represents the oauth client of a Session
+type SessionClient struct {
+	// The id of the client.
+	Id string `json:"id"`
+
+	// The name of the client.
+	Name string `json:"name"`
+
+	// The scopes the client may request.
+	AllowedScopes []string `json:"allowed_scopes"`
+
+	// The id of the room the client is installed in, if any.
+	RoomId int64 `json:"room_id,omitempty"`
+}
+
+// Gets an OAuth token for the requested grant type.
+//
+// Authentication required, with the credentials of the oauth client for the
+// client_credentials and refresh_token grants.
+// Accessible by group clients, room clients, users.
+func (s *OAuthService) GenerateToken(ctx context.Context, r *TokenRequest) (*Token, *PaginatedResponse, error) {
+	if r == nil || r.GrantType == "" {
+		return nil, nil, emptyParam
+	}
+
+	form, err := query.Values(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.newFormRequest(oauthTokenRoute, form)
+	if err != nil {
+		return nil, nil, err
+	}
+	if r.ClientId != "" {
+		req.SetBasicAuth(r.ClientId, r.ClientSecret)
+	}
+
+	token := new(Token)
+	resp, err := s.client.Do(ctx, req, token)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	if token.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+
+	return token, resp, nil
+}
+
+// Gets the OAuth session of an access token.
+//
+// Authentication required, with the token itself.
+// Accessible by group clients, room clients, users.
+func (s *OAuthService) GetSession(ctx context.Context, accessToken string) (*Session, *PaginatedResponse, error) {
+	if accessToken == "" {
+		return nil, nil, emptyParam
+	}
+
+	req, err := s.client.Get(fmt.Sprintf(oauthSessionRoute, accessToken))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session := new(Session)
+	resp, err := s.client.Do(ctx, req, session)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return session, resp, nil
+}
+
+// Deletes the OAuth session of an access token, revoking it.
+//
+// Authentication required, with the token itself.
+// Accessible by group clients, room clients, users.
+func (s *OAuthService) DeleteSession(ctx context.Context, accessToken string) (*PaginatedResponse, error) {
+	if accessToken == "" {
+		return nil, emptyParam
+	}
+
+	req, err := s.client.Delete(fmt.Sprintf(oauthSessionRoute, accessToken))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// TokenSource returns an oauth2.TokenSource generating tokens with r, which
+// are reused until they expire. Tokens issued with a refresh token are
+// renewed with the refresh_token grant.
+//
+// The client of the service should not itself be authenticated with the
+// returned TokenSource, use a separate client for generating tokens:
+//
+//	ts := hipchat.NewClient(nil).OAuth.TokenSource(ctx, &hipchat.TokenRequest{
+//		GrantType:    hipchat.GrantTypeClientCredentials,
+//		Scopes:       []string{"send_notification"},
+//		ClientId:     oauthId,
+//		ClientSecret: oauthSecret,
+//	})
+//	client := hipchat.NewClient(oauth2.NewClient(ctx, ts))
+func (s *OAuthService) TokenSource(ctx context.Context, r *TokenRequest) oauth2.TokenSource {
+	return oauth2.ReuseTokenSource(nil, &tokenSource{ctx: ctx, service: s, request: r})
+}
+
+// tokenSource generates a new token every time it's asked for one.
+type tokenSource struct {
+	ctx          context.Context
+	service      *OAuthService
+	request      *TokenRequest
+	refreshToken string
+}
+
+func (ts *tokenSource) Token() (*oauth2.Token, error) {
+	if ts.request == nil {
+		return nil, emptyParam
+	}
+
+	r := *ts.request
+	if ts.refreshToken != "" {
+		r.GrantType = GrantTypeRefreshToken
+		r.RefreshToken = ts.refreshToken
+	}
+
+	token, _, err := ts.service.GenerateToken(ts.ctx, &r)
+	if err != nil && ts.refreshToken != "" {
+		// The refresh token may have been revoked, start over with the original grant
+		ts.refreshToken = ""
+		token, _, err = ts.service.GenerateToken(ts.ctx, ts.request)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if token.RefreshToken != "" {
+		ts.refreshToken = token.RefreshToken
+	}
+
+	return &oauth2.Token{
+		AccessToken:  token.AccessToken,
+		TokenType:    token.TokenType,
+		RefreshToken: token.RefreshToken,
+		Expiry:       token.Expiry,
+	}, nil
+}
@@ -0,0 +1,160 @@
+package hipchat
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"time"
+)
+
+func (suite *HipChatClientTestSuite) TestOAuthService_GenerateToken() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf("/%s/%s", apiVersion2, oauthTokenRoute)
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodPost)
+		assert.Equal(contentTypeForm, r.Header.Get("Content-Type"))
+
+		id, secret, ok := r.BasicAuth()
+		assert.True(ok)
+		assert.Equal("oauth-1", id)
+		assert.Equal("s3cr3t", secret)
+
+		r.ParseForm()
+		assert.Equal(GrantTypeClientCredentials, r.PostForm.Get("grant_type"))
+		assert.Equal("send_notification view_room", r.PostForm.Get("scope"))
+		assert.Empty(r.PostForm.Get("client_id"))
+
+		fmt.Fprint(w, `{"access_token":"t0k3n","expires_in":3600,"group_id":10,"group_name":"Acme",
+			"scope":"send_notification view_room","token_type":"bearer"}`)
+	})
+
+	start := time.Now()
+	token, _, err := suite.client.OAuth.GenerateToken(context.Background(), &TokenRequest{
+		GrantType:    GrantTypeClientCredentials,
+		Scopes:       []string{"send_notification", "view_room"},
+		ClientId:     "oauth-1",
+		ClientSecret: "s3cr3t",
+	})
+	assert.Nil(err)
+
+	assert.Equal("t0k3n", token.AccessToken)
+	assert.Equal("bearer", token.TokenType)
+	assert.Equal(int64(3600), token.ExpiresIn)
+	assert.Equal([]string{"send_notification", "view_room"}, token.Scopes)
+	assert.Equal(int64(10), token.GroupId)
+	assert.Equal("Acme", token.GroupName)
+	assert.True(token.Expiry.After(start.Add(59 * time.Minute)))
+
+	_, _, err = suite.client.OAuth.GenerateToken(context.Background(), &TokenRequest{})
+	assert.EqualError(err, emptyParam.Error())
+}
+
+func (suite *HipChatClientTestSuite) TestOAuthService_GetSession() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(oauthSessionRoute, "t0k3n")
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodGet)
+		fmt.Fprint(w, `{"access_token":"t0k3n","expires":"2017-10-17T12:00:00+00:00","scopes":["view_room"],
+			"client":{"id":"oauth-1","name":"Deploy bot","allowed_scopes":["view_room","send_notification"],"room_id":2},
+			"owner":{"id":1,"name":"Theo"}}`)
+	})
+
+	session, _, err := suite.client.OAuth.GetSession(context.Background(), "t0k3n")
+	assert.Nil(err)
+
+	want := &Session{
+		AccessToken: "t0k3n",
+		Expires:     "2017-10-17T12:00:00+00:00",
+		Scopes:      []string{"view_room"},
+		Client: &SessionClient{
+			Id:            "oauth-1",
+			Name:          "Deploy bot",
+			AllowedScopes: []string{"view_room", "send_notification"},
+			RoomId:        2,
+		},
+		Owner: &UserListItem{Id: 1, Name: "Theo"},
+	}
+	assert.Equal(want, session)
+
+	_, _, err = suite.client.OAuth.GetSession(context.Background(), "")
+	assert.EqualError(err, emptyParam.Error())
+}
+
+func (suite *HipChatClientTestSuite) TestOAuthService_DeleteSession() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(oauthSessionRoute, "t0k3n")
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodDelete)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	resp, err := suite.client.OAuth.DeleteSession(context.Background(), "t0k3n")
+	assert.Nil(err)
+	assert.Equal(http.StatusNoContent, resp.StatusCode)
+}
+
+func (suite *HipChatClientTestSuite) TestOAuthService_TokenSource() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf("/%s/%s", apiVersion2, oauthTokenRoute)
+
+	var grants []string
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		grant := r.PostForm.Get("grant_type")
+		grants = append(grants, grant)
+
+		switch {
+		case grant == GrantTypeRefreshToken && r.PostForm.Get("refresh_token") == "revoked":
+			http.Error(w, `{"error":{"code":401,"message":"Invalid refresh token"}}`, http.StatusUnauthorized)
+		case grant == GrantTypeRefreshToken:
+			assert.Equal("r1", r.PostForm.Get("refresh_token"))
+			fmt.Fprint(w, `{"access_token":"t2","expires_in":1,"refresh_token":"revoked","token_type":"bearer"}`)
+		default:
+			fmt.Fprintf(w, `{"access_token":"t%d","expires_in":1,"refresh_token":"r1","token_type":"bearer"}`, len(grants))
+		}
+	})
+
+	ts := suite.client.OAuth.TokenSource(context.Background(), &TokenRequest{GrantType: GrantTypePersonal, Username: "theo"})
+
+	// Tokens expiring within the oauth2 expiry delta are renewed on every call
+	token, err := ts.Token()
+	assert.Nil(err)
+	assert.Equal("t1", token.AccessToken)
+	assert.Equal("Bearer", token.Type())
+
+	token, err = ts.Token()
+	assert.Nil(err)
+	assert.Equal("t2", token.AccessToken)
+
+	// Revoked refresh tokens fall back to the original grant
+	token, err = ts.Token()
+	assert.Nil(err)
+	assert.Equal("t4", token.AccessToken)
+
+	assert.Equal([]string{GrantTypePersonal, GrantTypeRefreshToken, GrantTypeRefreshToken, GrantTypePersonal}, grants)
+}
+
+func (suite *HipChatClientTestSuite) TestOAuthService_TokenSourceReuse() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf("/%s/%s", apiVersion2, oauthTokenRoute)
+
+	calls := 0
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"access_token":"t0k3n","expires_in":3600,"token_type":"bearer"}`)
+	})
+
+	ts := suite.client.OAuth.TokenSource(context.Background(), &TokenRequest{GrantType: GrantTypeClientCredentials})
+	for i := 0; i < 3; i++ {
+		token, err := ts.Token()
+		assert.Nil(err)
+		assert.Equal("t0k3n", token.AccessToken)
+	}
+	assert.Equal(1, calls)
+}
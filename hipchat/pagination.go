@@ -0,0 +1,159 @@
+package hipchat
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// PageFetcher fetches the page of results described by opt. The returned page
+// must be a slice, an empty one marks the end of the results.
+type PageFetcher func(ctx context.Context, opt ListOptions) (interface{}, *PaginatedResponse, error)
+
+// PageIterator lazily walks the pages of a paginated list endpoint. Pages are
+// only fetched when Next is called, following the Links.Next of every response
+// until there are no more results:
+//
+//	it := hipchat.NewPageIterator(hipchat.ListOptions{MaxResults: 100}, fetch)
+//	for it.Next(ctx) {
+//		rooms := it.Page().([]*hipchat.RoomListItem)
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type PageIterator struct {
+	fetch    PageFetcher
+	opt      ListOptions
+	prefetch bool
+
+	page    interface{}
+	resp    *PaginatedResponse
+	err     error
+	done    bool
+	pending chan pageResult
+}
+
+type pageResult struct {
+	page interface{}
+	resp *PaginatedResponse
+	err  error
+}
+
+// NewPageIterator returns a PageIterator starting at the page described by opt.
+func NewPageIterator(opt ListOptions, fetch PageFetcher) *PageIterator {
+	return &PageIterator{fetch: fetch, opt: opt}
+}
+
+// Prefetch makes the iterator fetch the next page concurrently while the
+// current one is being consumed.
+func (it *PageIterator) Prefetch() *PageIterator {
+	it.prefetch = true
+	return it
+}
+
+// Next advances the iterator to the next page. It returns false when there are
+// no more pages, when a request fails or when ctx is done.
+func (it *PageIterator) Next(ctx context.Context) bool {
+	if it.done || it.err != nil {
+		return false
+	}
+	if err := ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+
+	var r pageResult
+	if it.pending != nil {
+		select {
+		case r = <-it.pending:
+		case <-ctx.Done():
+			it.err = ctx.Err()
+			return false
+		}
+		it.pending = nil
+	} else {
+		r = it.fetchPage(ctx, it.opt)
+	}
+
+	if r.err != nil {
+		it.err = r.err
+		return false
+	}
+
+	n := reflect.ValueOf(r.page).Len()
+	if n == 0 {
+		it.done = true
+		return false
+	}
+	it.page, it.resp = r.page, r.resp
+
+	next, ok := nextListOptions(it.opt, r.resp, n)
+	if !ok {
+		it.done = true
+		return true
+	}
+	it.opt = next
+
+	if it.prefetch {
+		it.pending = make(chan pageResult, 1)
+		go func(pending chan<- pageResult, opt ListOptions) {
+			pending <- it.fetchPage(ctx, opt)
+		}(it.pending, next)
+	}
+
+	return true
+}
+
+// Page returns the current page. It is a slice of the type returned by the PageFetcher.
+func (it *PageIterator) Page() interface{} {
+	return it.page
+}
+
+// Response returns the response of the current page.
+func (it *PageIterator) Response() *PaginatedResponse {
+	return it.resp
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *PageIterator) Err() error {
+	return it.err
+}
+
+func (it *PageIterator) fetchPage(ctx context.Context, opt ListOptions) pageResult {
+	page, resp, err := it.fetch(ctx, opt)
+	if err == nil && reflect.ValueOf(page).Kind() != reflect.Slice {
+		err = invalidPage
+	}
+
+	return pageResult{page, resp, err}
+}
+
+// nextListOptions returns the options for the page following the one fetched
+// with opt. The start index and page size are taken from the next link, when the
+// response has one.
+func nextListOptions(opt ListOptions, resp *PaginatedResponse, n int) (ListOptions, bool) {
+	if resp == nil || resp.Links == nil || resp.Links.Next == "" {
+		return opt, false
+	}
+
+	next := opt
+	next.StartIndex = opt.StartIndex + n
+	if u, err := url.Parse(resp.Links.Next); err == nil {
+		q := u.Query()
+		if v, err := strconv.Atoi(q.Get("start-index")); err == nil {
+			next.StartIndex = v
+		}
+		if v, err := strconv.Atoi(q.Get("max-results")); err == nil {
+			next.MaxResults = v
+		}
+	}
+
+	// Guard against links that would never move forward
+	if next.StartIndex <= opt.StartIndex {
+		return opt, false
+	}
+
+	return next, true
+}
@@ -0,0 +1,137 @@
+package hipchat
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// handleRoomPages serves total rooms in pages of size rooms, with next links
+// pointing to the following page.
+func (suite *HipChatClientTestSuite) handleRoomPages(total int, size int) *int32 {
+	var calls int32
+	route := fmt.Sprintf("/%s/%s", apiVersion2, listRoomsRoute)
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		start, _ := strconv.Atoi(r.URL.Query().Get("start-index"))
+
+		var items []string
+		for i := start; i < start+size && i < total; i++ {
+			items = append(items, fmt.Sprintf(`{"id":%d}`, i))
+		}
+
+		links := `"self":"self"`
+		if start+size < total {
+			links += fmt.Sprintf(`,"next":"%s?start-index=%d&max-results=%d"`, route, start+size, size)
+		}
+
+		fmt.Fprintf(w, `{"items":[%s],"startIndex":%d,"maxResults":%d,"links":{%s}}`,
+			strings.Join(items, ","), start, size, links)
+	})
+
+	return &calls
+}
+
+func (suite *HipChatClientTestSuite) TestRoomsService_ListAllRooms() {
+	assert := assert.New(suite.T())
+	calls := suite.handleRoomPages(5, 2)
+
+	opt := &RoomsListOptions{IncludePrivate: true}
+	opt.MaxResults = 2
+	it := suite.client.Rooms.ListAllRooms(opt)
+
+	// Pages are only fetched once iteration starts
+	assert.Equal(int32(0), atomic.LoadInt32(calls))
+
+	var ids []int64
+	for it.Next(context.Background()) {
+		ids = append(ids, it.Room().Id)
+	}
+
+	assert.Nil(it.Err())
+	assert.Equal([]int64{0, 1, 2, 3, 4}, ids)
+	assert.Equal(int32(3), atomic.LoadInt32(calls))
+}
+
+func (suite *HipChatClientTestSuite) TestRoomsService_ListAllRoomsPrefetch() {
+	assert := assert.New(suite.T())
+	calls := suite.handleRoomPages(6, 3)
+
+	opt := &RoomsListOptions{}
+	opt.MaxResults = 3
+	it := suite.client.Rooms.ListAllRooms(opt).Prefetch()
+
+	var ids []int64
+	for it.Next(context.Background()) {
+		ids = append(ids, it.Room().Id)
+	}
+
+	assert.Nil(it.Err())
+	assert.Equal([]int64{0, 1, 2, 3, 4, 5}, ids)
+	assert.Equal(int32(2), atomic.LoadInt32(calls))
+}
+
+func (suite *HipChatClientTestSuite) TestRoomsService_ListAllRoomsCanceled() {
+	assert := assert.New(suite.T())
+	calls := suite.handleRoomPages(10, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	it := suite.client.Rooms.ListAllRooms(nil)
+
+	assert.True(it.Next(ctx))
+	assert.True(it.Next(ctx))
+	cancel()
+
+	assert.False(it.Next(ctx))
+	assert.Equal(context.Canceled, it.Err())
+	assert.Equal(int32(1), atomic.LoadInt32(calls))
+}
+
+func (suite *HipChatClientTestSuite) TestPageIterator_error() {
+	assert := assert.New(suite.T())
+
+	fetch := func(ctx context.Context, opt ListOptions) (interface{}, *PaginatedResponse, error) {
+		return nil, nil, emptyParam
+	}
+	it := NewPageIterator(ListOptions{}, fetch)
+	assert.False(it.Next(context.Background()))
+	assert.Equal(emptyParam, it.Err())
+
+	fetch = func(ctx context.Context, opt ListOptions) (interface{}, *PaginatedResponse, error) {
+		return "not a slice", nil, nil
+	}
+	it = NewPageIterator(ListOptions{}, fetch)
+	assert.False(it.Next(context.Background()))
+	assert.Equal(invalidPage, it.Err())
+}
+
+func (suite *HipChatClientTestSuite) TestPageIterator_nextListOptions() {
+	assert := assert.New(suite.T())
+	testCases := []struct {
+		name   string
+		opt    ListOptions
+		links  *PaginationLinks
+		n      int
+		want   ListOptions
+		wantOk bool
+	}{
+		{"TestNoLinks", ListOptions{0, 10}, nil, 10, ListOptions{0, 10}, false},
+		{"TestNoNextLink", ListOptions{0, 10}, &PaginationLinks{Self: "self"}, 10, ListOptions{0, 10}, false},
+		{"TestNextLink", ListOptions{0, 10}, &PaginationLinks{Next: "room?start-index=10&max-results=10"}, 10, ListOptions{10, 10}, true},
+		{"TestNextLinkWithoutQuery", ListOptions{5, 0}, &PaginationLinks{Next: "room"}, 5, ListOptions{10, 0}, true},
+		{"TestNextLinkGoingBackwards", ListOptions{10, 10}, &PaginationLinks{Next: "room?start-index=0"}, 10, ListOptions{10, 10}, false},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			got, ok := nextListOptions(tc.opt, &PaginatedResponse{Links: tc.links}, tc.n)
+			assert.Equal(tc.wantOk, ok)
+			assert.Equal(tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package hipchat
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// HipChat allows 500 API requests per 5 minutes for every token.
+	defaultRateLimit         = 500
+	defaultRateLimitInterval = 5 * time.Minute
+
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// RateLimiter is a token bucket limiting how many requests a Client sends.
+// The bucket holds up to limit tokens and is refilled at a rate of limit tokens
+// per interval. Since HipChat enforces its limits per token, clients sharing
+// the same token should share the same RateLimiter.
+type RateLimiter struct {
+	mu       sync.Mutex
+	limit    float64
+	interval time.Duration
+	tokens   float64
+	last     time.Time
+}
+
+// NewRateLimiter returns a RateLimiter allowing limit requests per interval.
+// Both limit and interval must be positive.
+func NewRateLimiter(limit int, interval time.Duration) (*RateLimiter, error) {
+	if limit <= 0 || interval <= 0 {
+		return nil, invalidRateLimit
+	}
+
+	return &RateLimiter{
+		limit:    float64(limit),
+		interval: interval,
+		tokens:   float64(limit),
+		last:     time.Now(),
+	}, nil
+}
+
+// NewDefaultRateLimiter returns a RateLimiter matching HipChat's default limit
+// of 500 requests per 5 minutes.
+func NewDefaultRateLimiter() *RateLimiter {
+	l, _ := NewRateLimiter(defaultRateLimit, defaultRateLimitInterval)
+	return l
+}
+
+// Wait blocks until a request may be sent. It returns ctx.Err() if the context
+// is canceled or times out before then.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.tokens += now.Sub(l.last).Seconds() * l.limit / l.interval.Seconds()
+		if l.tokens > l.limit {
+			l.tokens = l.limit
+		}
+		l.last = now
+
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+
+		wait := time.Duration((1 - l.tokens) * float64(l.interval) / l.limit)
+		l.mu.Unlock()
+
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// RetryPolicy configures how a Client retries requests failing with
+// 429 Too Many Requests or a 5xx status code. Requests failing with a 5xx
+// status code may have been processed, so only idempotent requests are retried
+// then, unless RetryNonIdempotent is set.
+type RetryPolicy struct {
+	// The maximum number of retries for a single request.
+	MaxRetries int
+
+	// The initial backoff, doubled after every attempt.
+	//
+	// Defaults to 500ms.
+	MinBackoff time.Duration
+
+	// The maximum backoff between two attempts.
+	//
+	// Defaults to 30s.
+	MaxBackoff time.Duration
+
+	// Whether to retry POST requests failing with a 5xx status code, which may
+	// send a message twice.
+	RetryNonIdempotent bool
+}
+
+// backoff returns how long to wait before retrying attempt. Rate limited
+// requests wait until the rate limit window resets, when it's known, but no
+// longer than MaxBackoff.
+func (p *RetryPolicy) backoff(attempt int, err error) time.Duration {
+	min, max := p.MinBackoff, p.MaxBackoff
+	if min <= 0 {
+		min = defaultMinBackoff
+	}
+	if max <= 0 {
+		max = defaultMaxBackoff
+	}
+
+	if e, ok := err.(*RateLimitError); ok {
+		if wait := time.Until(e.Rate.Reset); wait > 0 {
+			wait += jitter(time.Second)
+			if wait > max {
+				wait = max
+			}
+			return wait
+		}
+	}
+
+	d := min << uint(attempt)
+	if d > max || d <= 0 {
+		d = max
+	}
+
+	// Wait between half and the full backoff
+	return d/2 + jitter(d/2)
+}
+
+// shouldRetry reports whether req, which failed with err, can be retried.
+func (p *RetryPolicy) shouldRetry(req *http.Request, attempt int, err error) bool {
+	if p == nil || attempt >= p.MaxRetries {
+		return false
+	}
+
+	switch e := err.(type) {
+	case *RateLimitError:
+		return true
+	case *ErrorResponse:
+		return e.Code >= http.StatusInternalServerError && (p.RetryNonIdempotent || isIdempotent(req.Method))
+	}
+
+	return false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+// rewindBody resets the request body so the request can be sent again.
+func rewindBody(req *http.Request) bool {
+	if req.Body == nil || req.Body == http.NoBody {
+		return true
+	}
+	if req.GetBody == nil {
+		return false
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return false
+	}
+	req.Body = body
+
+	return true
+}
+
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(d)))
+}
+
+// sleep waits for d or until ctx is done, whichever happens first.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
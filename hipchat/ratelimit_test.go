@@ -0,0 +1,228 @@
+package hipchat
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type HipChatRateLimiterTestSuite struct {
+	suite.Suite
+}
+
+func TestHipChatRateLimiterTestSuite(t *testing.T) {
+	suite.Run(t, new(HipChatRateLimiterTestSuite))
+}
+
+func (suite *HipChatRateLimiterTestSuite) TestRateLimiter_Wait() {
+	assert := assert.New(suite.T())
+	l, err := NewRateLimiter(2, 100*time.Millisecond)
+	assert.Nil(err)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		assert.Nil(l.Wait(context.Background()))
+	}
+
+	// The first two requests use the full bucket, the third waits for a refill
+	assert.True(time.Since(start) >= 40*time.Millisecond)
+}
+
+func (suite *HipChatRateLimiterTestSuite) TestRateLimiter_WaitCanceled() {
+	assert := assert.New(suite.T())
+	l, _ := NewRateLimiter(1, time.Hour)
+	assert.Nil(l.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(context.DeadlineExceeded, l.Wait(ctx))
+}
+
+func (suite *HipChatRateLimiterTestSuite) TestNewRateLimiter_invalid() {
+	assert := assert.New(suite.T())
+	for _, tc := range []struct {
+		limit    int
+		interval time.Duration
+	}{{0, time.Second}, {-1, time.Second}, {1, 0}, {1, -time.Second}} {
+		l, err := NewRateLimiter(tc.limit, tc.interval)
+		assert.Nil(l)
+		assert.Equal(invalidRateLimit, err)
+	}
+}
+
+func (suite *HipChatRateLimiterTestSuite) TestNewDefaultRateLimiter() {
+	assert := assert.New(suite.T())
+	l := NewDefaultRateLimiter()
+
+	assert.Equal(float64(500), l.limit)
+	assert.Equal(5*time.Minute, l.interval)
+}
+
+func (suite *HipChatRateLimiterTestSuite) TestRetryPolicy_backoff() {
+	assert := assert.New(suite.T())
+	p := &RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	for attempt, max := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		d := p.backoff(attempt, &ErrorResponse{Code: 503})
+		assert.True(d >= max*time.Millisecond/2, fmt.Sprintf("attempt %d: %v", attempt, d))
+		assert.True(d <= max*time.Millisecond, fmt.Sprintf("attempt %d: %v", attempt, d))
+	}
+
+	reset := time.Now().Add(500 * time.Millisecond)
+	d := p.backoff(0, &RateLimitError{Rate: Rate{Reset: reset}})
+	assert.True(d >= 400*time.Millisecond, d.String())
+	assert.True(d <= time.Second, d.String())
+
+	// Rate limit resets are capped at the maximum backoff
+	reset = time.Now().Add(time.Hour)
+	d = p.backoff(0, &RateLimitError{Rate: Rate{Reset: reset}})
+	assert.Equal(time.Second, d)
+}
+
+func (suite *HipChatRateLimiterTestSuite) TestRetryPolicy_shouldRetry() {
+	assert := assert.New(suite.T())
+	p := &RetryPolicy{MaxRetries: 1}
+	get, _ := http.NewRequest(http.MethodGet, "/", nil)
+	post, _ := http.NewRequest(http.MethodPost, "/", nil)
+
+	assert.True(p.shouldRetry(get, 0, &RateLimitError{}))
+	assert.True(p.shouldRetry(get, 0, &ErrorResponse{Code: 502}))
+	assert.False(p.shouldRetry(get, 0, &ErrorResponse{Code: 404}))
+	assert.False(p.shouldRetry(get, 0, emptyParam))
+	assert.False(p.shouldRetry(get, 0, nil))
+	assert.False(p.shouldRetry(get, 1, &RateLimitError{}))
+
+	// POST requests may have been processed when failing with a 5xx status code
+	assert.True(p.shouldRetry(post, 0, &RateLimitError{}))
+	assert.False(p.shouldRetry(post, 0, &ErrorResponse{Code: 502}))
+	p.RetryNonIdempotent = true
+	assert.True(p.shouldRetry(post, 0, &ErrorResponse{Code: 502}))
+
+	var nilPolicy *RetryPolicy
+	assert.False(nilPolicy.shouldRetry(get, 0, &RateLimitError{}))
+}
+
+func (suite *HipChatRateLimiterTestSuite) TestRateLimitError_withoutResponse() {
+	assert := assert.New(suite.T())
+	err := &RateLimitError{Message: "slow down", Rate: Rate{Reset: time.Now().Add(time.Minute)}}
+
+	assert.NotPanics(func() { _ = err.Error() })
+	assert.Contains(err.Error(), "429 slow down; rate reset in")
+}
+
+func (suite *HipChatClientTestSuite) TestDo_retry() {
+	assert := assert.New(suite.T())
+	suite.client.RetryPolicy = &RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond, RetryNonIdempotent: true}
+
+	calls := 0
+	suite.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(`{"message":"hello"}`+"\n", string(body))
+
+		switch calls {
+		case 1:
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set(headerRateReset, fmt.Sprint(time.Now().Add(-time.Second).Unix()))
+			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+		default:
+			fmt.Fprint(w, `{"id":"1"}`)
+		}
+	})
+
+	req, _ := suite.client.Post(".", sendMessageBody{"hello"})
+	m := new(RoomMessage)
+	resp, err := suite.client.Do(context.Background(), req, m)
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	assert.Equal("1", m.Id)
+	assert.Equal(3, calls)
+}
+
+func (suite *HipChatClientTestSuite) TestDo_retryIdempotentOnly() {
+	assert := assert.New(suite.T())
+	suite.client.RetryPolicy = &RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	calls := 0
+	suite.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "Bad Gateway", http.StatusBadGateway)
+	})
+
+	req, _ := suite.client.Post(".", sendMessageBody{"hello"})
+	resp, err := suite.client.Do(context.Background(), req, nil)
+	assert.Equal(http.StatusBadGateway, resp.StatusCode)
+	assert.IsType(&ErrorResponse{}, err)
+	assert.Equal(1, calls)
+}
+
+func (suite *HipChatClientTestSuite) TestDo_retryExhausted() {
+	assert := assert.New(suite.T())
+	suite.client.RetryPolicy = &RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	calls := 0
+	suite.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	})
+
+	req, _ := suite.client.Get(".")
+	resp, err := suite.client.Do(context.Background(), req, nil)
+	assert.Equal(http.StatusInternalServerError, resp.StatusCode)
+	assert.IsType(&ErrorResponse{}, err)
+	assert.Equal(3, calls)
+}
+
+func (suite *HipChatClientTestSuite) TestDo_retryUpload() {
+	assert := assert.New(suite.T())
+	suite.client.RetryPolicy = &RetryPolicy{MaxRetries: 1, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond, RetryNonIdempotent: true}
+
+	calls := 0
+	suite.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Contains(string(body), "Hello World")
+		assert.Contains(string(body), "message")
+
+		if calls == 1 {
+			http.Error(w, "Bad Gateway", http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	f := bytes.NewReader([]byte("Hello World"))
+	req, _ := suite.client.NewUploadRequest(".", f, int64(f.Len()), "text/plain", sendMessageBody{"message"}, "hello.txt")
+	resp, err := suite.client.Do(context.Background(), req, nil)
+	assert.Nil(err)
+	assert.Equal(http.StatusNoContent, resp.StatusCode)
+	assert.Equal(2, calls)
+}
+
+func (suite *HipChatClientTestSuite) TestDo_rateLimiter() {
+	assert := assert.New(suite.T())
+	suite.client.RateLimiter, _ = NewRateLimiter(1, time.Hour)
+
+	suite.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	req, _ := suite.client.Get(".")
+	_, err := suite.client.Do(context.Background(), req, nil)
+	assert.Nil(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	req, _ = suite.client.Get(".")
+	_, err = suite.client.Do(ctx, req, nil)
+	assert.Equal(context.DeadlineExceeded, err)
+}
@@ -3,9 +3,8 @@ package hipchat
 import (
 	"context"
 	"fmt"
-	"mime"
+	"io"
 	"os"
-	"path/filepath"
 	"strings"
 )
 
@@ -16,17 +15,34 @@ const (
 	// Private Room access
 	RoomPrivacyPrivate = "private"
 
-	listRoomsRoute           = "room"
-	getRoomRoute             = "room/%v"
-	setRoomTopicRoute        = "room/%v/topic"
-	getRoomStatisticsRoute   = "room/%v/statistics"
-	shareLinkWithRoomRoute   = "room/%v/share/link"
-	getRoomParticipantsRoute = "room/%v/participant"
-	replyToRoomMessageRoute  = "room/%v/reply"
-	sendRoomMessageRoute     = "room/%v/message"
-	getRoomMembersRoute      = "room/%v/member"
-	inviteUserRoute          = "room/%v/invite"
-	shareFileRoute           = "room/%v/share/file"
+	// Notification background colors
+	ColorYellow = "yellow"
+	ColorGreen  = "green"
+	ColorRed    = "red"
+	ColorPurple = "purple"
+	ColorGray   = "gray"
+	ColorRandom = "random"
+
+	// Determines how the message is treated by HipChat's servers and rendered inside HipChat applications
+	MessageFormatHtml = "html"
+	MessageFormatText = "text"
+
+	listRoomsRoute             = "room"
+	getRoomRoute               = "room/%v"
+	setRoomTopicRoute          = "room/%v/topic"
+	getRoomStatisticsRoute     = "room/%v/statistics"
+	shareLinkWithRoomRoute     = "room/%v/share/link"
+	getRoomParticipantsRoute   = "room/%v/participant"
+	replyToRoomMessageRoute    = "room/%v/reply"
+	sendRoomMessageRoute       = "room/%v/message"
+	getRoomMembersRoute        = "room/%v/member"
+	inviteUserRoute            = "room/%v/invite"
+	shareFileRoute             = "room/%v/share/file"
+	sendRoomNotificationRoute  = "room/%v/notification"
+	viewRoomHistoryRoute       = "room/%v/history"
+	viewRecentRoomHistoryRoute = "room/%v/history/latest"
+	roomWebhookRoute           = "room/%v/extension/webhook"
+	listRoomWebhooksRoute      = "room/%v/webhook"
 )
 
 // RoomsService handles communication with the room related
@@ -130,6 +146,40 @@ type RoomMessage struct {
 	Timestamp string `json:"timestamp"`
 }
 
+// Notification represents a HipChat Room Notification
+type Notification struct {
+	// A label to be shown in addition to the sender's name.
+	From string `json:"from,omitempty"`
+
+	// Determines how the message is treated by HipChat's servers and rendered inside HipChat applications.
+	// Valid values: html, text.
+	//
+	// Defaults to 'html'.
+	MessageFormat string `json:"message_format,omitempty"`
+
+	// Background color for message.
+	// Valid values: yellow, green, red, purple, gray, random.
+	//
+	// Defaults to 'yellow'.
+	Color string `json:"color,omitempty"`
+
+	// The message id to to attach this notification to, for example if this notification is in
+	// response to a particular message.
+	AttachTo string `json:"attach_to,omitempty"`
+
+	// Whether this message should trigger a user notification (change the tab color, play a sound,
+	// notify mobile phones, etc). Each recipient's notification preferences are taken into account.
+	//
+	// Defaults to 'false'.
+	Notify bool `json:"notify,omitempty"`
+
+	// The message body. Valid length range: 1 - 10000.
+	Message string `json:"message"`
+
+	// An optional card to render along with the message.
+	Card *Card `json:"card,omitempty"`
+}
+
 // RoomsListOptions specifies the optional parameters to the
 // RoomService.ListRooms
 type RoomsListOptions struct {
@@ -156,6 +206,10 @@ type RoomParticipantsOptions struct {
 // Authentication required, with scope view_group or view_room.
 // Accessible by group clients, users.
 func (s *RoomsService) ListRooms(ctx context.Context, opt *RoomsListOptions) ([]*RoomListItem, *PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsListRooms); err != nil {
+		return nil, nil, err
+	}
+
 	opts, err := addUrlOptions(listRoomsRoute, opt)
 	if err != nil {
 		return nil, nil, err
@@ -175,11 +229,74 @@ func (s *RoomsService) ListRooms(ctx context.Context, opt *RoomsListOptions) ([]
 	return rooms.Items, resp, nil
 }
 
+// RoomIterator iterates over the rooms of all the pages returned by
+// RoomsService.ListRooms.
+type RoomIterator struct {
+	pages *PageIterator
+	items []*RoomListItem
+	room  *RoomListItem
+}
+
+// List all non-archived rooms for this group, fetching the pages lazily
+// while iterating.
+//
+// Authentication required, with scope view_group or view_room.
+// Accessible by group clients, users.
+func (s *RoomsService) ListAllRooms(opt *RoomsListOptions) *RoomIterator {
+	var o RoomsListOptions
+	if opt != nil {
+		o = *opt
+	}
+
+	fetch := func(ctx context.Context, lo ListOptions) (interface{}, *PaginatedResponse, error) {
+		pageOpt := o
+		pageOpt.ListOptions = lo
+		return s.ListRooms(ctx, &pageOpt)
+	}
+
+	return &RoomIterator{pages: NewPageIterator(o.ListOptions, fetch)}
+}
+
+// Prefetch makes the iterator fetch the next page of rooms concurrently.
+func (it *RoomIterator) Prefetch() *RoomIterator {
+	it.pages.Prefetch()
+	return it
+}
+
+// Next advances the iterator to the next room, fetching the next page when
+// needed. It returns false when there are no more rooms, when a request fails
+// or when ctx is done.
+func (it *RoomIterator) Next(ctx context.Context) bool {
+	for len(it.items) == 0 {
+		if !it.pages.Next(ctx) {
+			return false
+		}
+		it.items = it.pages.Page().([]*RoomListItem)
+	}
+
+	it.room, it.items = it.items[0], it.items[1:]
+	return true
+}
+
+// Room returns the current room.
+func (it *RoomIterator) Room() *RoomListItem {
+	return it.room
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *RoomIterator) Err() error {
+	return it.pages.Err()
+}
+
 // Get room details.
 //
 // Authentication required, with scope view_group or view_room.
 // Accessible by group clients, room clients, users.
 func (s *RoomsService) GetRoom(ctx context.Context, roomIdOrName string) (*Room, *PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsGetRoom); err != nil {
+		return nil, nil, err
+	}
+
 	var u, err = getRoomResourcePath(roomIdOrName, getRoomRoute)
 	if err != nil {
 		return nil, nil, err
@@ -204,6 +321,10 @@ func (s *RoomsService) GetRoom(ctx context.Context, roomIdOrName string) (*Room,
 // Authentication required, with scope admin_room.
 // Accessible by group clients, users.
 func (s *RoomsService) UpdateRoom(ctx context.Context, roomIdOrName string, room *Room) (*PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsUpdateRoom); err != nil {
+		return nil, err
+	}
+
 	var u, err = getRoomResourcePath(roomIdOrName, getRoomRoute)
 	if err != nil {
 		return nil, err
@@ -227,6 +348,10 @@ func (s *RoomsService) UpdateRoom(ctx context.Context, roomIdOrName string, room
 // Authentication required, with scope manage_rooms.
 // Accessible by group clients, users.
 func (s *RoomsService) DeleteRoom(ctx context.Context, roomIdOrName string) (*PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsDeleteRoom); err != nil {
+		return nil, err
+	}
+
 	var u, err = getRoomResourcePath(roomIdOrName, getRoomRoute)
 	if err != nil {
 		return nil, err
@@ -250,6 +375,10 @@ func (s *RoomsService) DeleteRoom(ctx context.Context, roomIdOrName string) (*Pa
 // Authentication required, with scope manage_rooms.
 // Accessible by group clients, users.
 func (s *RoomsService) CreateRoom(ctx context.Context, room *Room) (*Room, *PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsCreateRoom); err != nil {
+		return nil, nil, err
+	}
+
 	req, err := s.client.Post(listRoomsRoute, room)
 	if err != nil {
 		return nil, nil, err
@@ -272,6 +401,10 @@ func (s *RoomsService) CreateRoom(ctx context.Context, room *Room) (*Room, *Pagi
 // Authentication required, with scope admin_room.
 // Accessible by group clients, room clients, users.
 func (s *RoomsService) SetRoomTopic(ctx context.Context, roomIdOrName string, topic string) (*PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsSetRoomTopic); err != nil {
+		return nil, err
+	}
+
 	var u, err = getRoomResourcePath(roomIdOrName, setRoomTopicRoute)
 	if err != nil {
 		return nil, err
@@ -295,6 +428,10 @@ func (s *RoomsService) SetRoomTopic(ctx context.Context, roomIdOrName string, to
 // Authentication required, with scope view_group or view_room.
 // Accessible by group clients, room clients, users.
 func (s *RoomsService) GetRoomStatistics(ctx context.Context, roomIdOrName string) (*RoomStatistic, *PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsGetRoomStatistics); err != nil {
+		return nil, nil, err
+	}
+
 	var u, err = getRoomResourcePath(roomIdOrName, getRoomStatisticsRoute)
 	if err != nil {
 		return nil, nil, err
@@ -319,6 +456,10 @@ func (s *RoomsService) GetRoomStatistics(ctx context.Context, roomIdOrName strin
 // Authentication required, with scope send_message.
 // Accessible by users.
 func (s *RoomsService) ShareLinkWithRoom(ctx context.Context, roomIdOrName string, message string, link string) (*PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsShareLinkWithRoom); err != nil {
+		return nil, err
+	}
+
 	var u, err = getRoomResourcePath(roomIdOrName, shareLinkWithRoomRoute)
 	if err != nil {
 		return nil, err
@@ -342,6 +483,10 @@ func (s *RoomsService) ShareLinkWithRoom(ctx context.Context, roomIdOrName strin
 // Authentication required, with scope view_room.
 // Accessible by group clients, room clients, users.
 func (s *RoomsService) GetRoomParticipants(ctx context.Context, roomIdOrName string, opt *RoomParticipantsOptions) ([]*UserListItem, *PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsGetRoomParticipants); err != nil {
+		return nil, nil, err
+	}
+
 	var u, err = getRoomResourcePath(roomIdOrName, getRoomParticipantsRoute)
 	if err != nil {
 		return nil, nil, err
@@ -371,6 +516,10 @@ func (s *RoomsService) GetRoomParticipants(ctx context.Context, roomIdOrName str
 // Authentication required, with scope send_message.
 // Accessible by users.
 func (s *RoomsService) ReplyToRoomMessage(ctx context.Context, roomIdOrName string, messageId string, message string) (*PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsReplyToRoomMessage); err != nil {
+		return nil, err
+	}
+
 	var u, err = getRoomResourcePath(roomIdOrName, replyToRoomMessageRoute)
 	if err != nil {
 		return nil, err
@@ -394,6 +543,10 @@ func (s *RoomsService) ReplyToRoomMessage(ctx context.Context, roomIdOrName stri
 // Authentication required, with scope admin_room.
 // Accessible by users.
 func (s *RoomsService) InviteUser(ctx context.Context, roomIdOrName string, userIdOrName string, reason string) (*PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsInviteUser); err != nil {
+		return nil, err
+	}
+
 	var u, err = getRoomResourcePath(roomIdOrName, inviteUserRoute)
 	if err != nil {
 		return nil, err
@@ -426,6 +579,10 @@ func (s *RoomsService) InviteUser(ctx context.Context, roomIdOrName string, user
 // Authentication required, with scope send_message.
 // Accessible by users.
 func (s *RoomsService) SendRoomMessage(ctx context.Context, roomIdOrName string, message string) (*RoomMessage, *PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsSendRoomMessage); err != nil {
+		return nil, nil, err
+	}
+
 	var u, err = getRoomResourcePath(roomIdOrName, sendRoomMessageRoute)
 	if err != nil {
 		return nil, nil, err
@@ -445,17 +602,199 @@ func (s *RoomsService) SendRoomMessage(ctx context.Context, roomIdOrName string,
 	return m, resp, nil
 }
 
+// Send a room notification.
+//
+// Authentication required, with scope send_notification.
+// Accessible by group clients, room clients, users.
+func (s *RoomsService) SendRoomNotification(ctx context.Context, roomIdOrName string, notification *Notification) (*PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsSendRoomNotification); err != nil {
+		return nil, err
+	}
+
+	var u, err = getRoomResourcePath(roomIdOrName, sendRoomNotificationRoute)
+	if err != nil {
+		return nil, err
+	}
+
+	if notification == nil || notification.Message == "" {
+		return nil, emptyParam
+	}
+
+	if notification.Card != nil {
+		if err := notification.Card.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := s.client.Post(u, notification)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// Fetch chat history for this room.
+//
+// Authentication required, with scope view_group or view_messages.
+// Accessible by group clients, room clients, users.
+func (s *RoomsService) ViewRoomHistory(ctx context.Context, roomIdOrName string, opt *HistoryOptions) ([]*HistoryMessage, *PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsViewRoomHistory); err != nil {
+		return nil, nil, err
+	}
+
+	var u, err = getRoomResourcePath(roomIdOrName, viewRoomHistoryRoute)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	opts, err := addUrlOptions(u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.Get(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var history *historyListResponse
+	resp, err := s.client.Do(ctx, req, &history)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return history.Items, resp, nil
+}
+
+// Fetch latest chat history for this room.
+//
+// Authentication required, with scope view_group or view_messages.
+// Accessible by group clients, room clients, users.
+func (s *RoomsService) ViewRecentRoomHistory(ctx context.Context, roomIdOrName string, opt *RecentHistoryOptions) ([]*HistoryMessage, *PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsViewRecentRoomHistory); err != nil {
+		return nil, nil, err
+	}
+
+	var u, err = getRoomResourcePath(roomIdOrName, viewRecentRoomHistoryRoute)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	opts, err := addUrlOptions(u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.Get(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var history *historyListResponse
+	resp, err := s.client.Do(ctx, req, &history)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return history.Items, resp, nil
+}
+
+// Fetch one specific message by id, along with the messages surrounding it.
+//
+// Authentication required, with scope view_group or view_messages.
+// Accessible by group clients, room clients, users.
+func (s *RoomsService) GetRoomMessage(ctx context.Context, roomIdOrName string, messageId string, opt *MessageOptions) (*RoomMessageContext, *PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsGetRoomMessage); err != nil {
+		return nil, nil, err
+	}
+
+	var u, err = getRoomResourcePath(roomIdOrName, viewRoomHistoryRoute)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if messageId == "" {
+		return nil, nil, emptyParam
+	}
+
+	u = strings.Join([]string{u, messageId}, "/")
+	opts, err := addUrlOptions(u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.Get(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m := new(RoomMessageContext)
+	resp, err := s.client.Do(ctx, req, m)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return m, resp, nil
+}
+
+// Delete a message from a room.
+//
+// Authentication required, with scope admin_room.
+// Accessible by group clients, room clients, users.
+func (s *RoomsService) DeleteRoomMessage(ctx context.Context, roomIdOrName string, messageId string) (*PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsDeleteRoomMessage); err != nil {
+		return nil, err
+	}
+
+	var u, err = getRoomResourcePath(roomIdOrName, viewRoomHistoryRoute)
+	if err != nil {
+		return nil, err
+	}
+
+	if messageId == "" {
+		return nil, emptyParam
+	}
+
+	u = strings.Join([]string{u, messageId}, "/")
+	req, err := s.client.Delete(u)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
 // Gets all members for this private room.
 //
 // Authentication required, with scope view_room.
 // Accessible by group clients, room clients, users.
 func (s *RoomsService) GetRoomMembers(ctx context.Context, roomIdOrName string, opt *ListOptions) ([]*UserListItem, *PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsGetRoomMembers); err != nil {
+		return nil, nil, err
+	}
+
 	var u, err = getRoomResourcePath(roomIdOrName, getRoomMembersRoute)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	req, err := s.client.Get(u)
+	opts, err := addUrlOptions(u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.Get(opts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -469,12 +808,42 @@ func (s *RoomsService) GetRoomMembers(ctx context.Context, roomIdOrName string,
 	return members.Items, resp, nil
 }
 
+// Gets all members for this private room, paging through the results until
+// there are no more.
+//
+// Authentication required, with scope view_room.
+// Accessible by group clients, room clients, users.
+func (s *RoomsService) GetAllRoomMembers(ctx context.Context, roomIdOrName string) ([]*UserListItem, error) {
+	if roomIdOrName == "" {
+		return nil, emptyParam
+	}
+
+	fetch := func(ctx context.Context, opt ListOptions) (interface{}, *PaginatedResponse, error) {
+		return s.GetRoomMembers(ctx, roomIdOrName, &opt)
+	}
+
+	var members []*UserListItem
+	it := NewPageIterator(ListOptions{}, fetch)
+	for it.Next(ctx) {
+		members = append(members, it.Page().([]*UserListItem)...)
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
 // Adds a member to a private room and sends member's unavailable presence to all
 // room members asynchronously.
 //
 // Authentication required, with scope admin_room.
 // Accessible by group clients, room clients, users.
 func (s *RoomsService) AddRoomMember(ctx context.Context, roomIdOrName string, userIdOrName string) (*PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsAddRoomMember); err != nil {
+		return nil, err
+	}
+
 	var u, err = getRoomResourcePath(roomIdOrName, getRoomMembersRoute)
 	if err != nil {
 		return nil, err
@@ -504,6 +873,10 @@ func (s *RoomsService) AddRoomMember(ctx context.Context, roomIdOrName string, u
 // Authentication required, with scope admin_room.
 // Accessible by group clients, room clients, users.
 func (s *RoomsService) RemoveRoomMember(ctx context.Context, roomIdOrName string, userIdOrName string) (*PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsRemoveRoomMember); err != nil {
+		return nil, err
+	}
+
 	var u, err = getRoomResourcePath(roomIdOrName, getRoomMembersRoute)
 	if err != nil {
 		return nil, err
@@ -531,27 +904,57 @@ func (s *RoomsService) RemoveRoomMember(ctx context.Context, roomIdOrName string
 //
 // Format the request as multipart/related with a single part of content-type
 // application/json and a second part containing your file.
+//
+// Authentication required, with scope send_message.
+// Accessible by users.
 func (s *RoomsService) ShareFile(ctx context.Context, roomIdOrName string, file *os.File, message string) (*PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsShareFile); err != nil {
+		return nil, err
+	}
+
 	var u, err = getRoomResourcePath(roomIdOrName, shareFileRoute)
 	if err != nil {
 		return nil, err
 	}
 
-	var m sendMessageBody
-	if message != "" {
-		m = sendMessageBody{message}
+	req, err := s.client.newShareFileRequest(u, file, message)
+	if err != nil {
+		return nil, err
 	}
 
-	stat, err := file.Stat()
+	resp, err := s.client.Do(ctx, req, nil)
 	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// Share the contents of reader with the room as a file named fileName.
+//
+// When mediaType is empty it's guessed from the extension of fileName, or
+// sniffed from the contents if the extension is unknown. Files larger than
+// MaxFileSize are rejected with a FileTooLargeError.
+//
+// Authentication required, with scope send_message.
+// Accessible by users.
+func (s *RoomsService) ShareReader(
+	ctx context.Context,
+	roomIdOrName string,
+	reader io.Reader,
+	fileName string,
+	mediaType string,
+	message string) (*PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsShareReader); err != nil {
 		return nil, err
 	}
-	if stat.IsDir() {
-		return nil, invalidFileUpload
+
+	var u, err = getRoomResourcePath(roomIdOrName, shareFileRoute)
+	if err != nil {
+		return nil, err
 	}
 
-	mediaType := mime.TypeByExtension(filepath.Ext(file.Name()))
-	req, err := s.client.NewUploadRequest(u, file, stat.Size(), mediaType, m, baseFileName(file.Name()))
+	req, err := s.client.newShareReaderRequest(u, reader, fileName, mediaType, message)
 	if err != nil {
 		return nil, err
 	}
@@ -564,6 +967,154 @@ func (s *RoomsService) ShareFile(ctx context.Context, roomIdOrName string, file
 	return resp, nil
 }
 
+// Creates a webhook for the room, or replaces the webhook registered with the
+// same key. Registering the same webhook more than once is safe.
+//
+// Authentication required, with scope admin_room.
+// Accessible by group clients, room clients, users.
+func (s *RoomsService) CreateRoomWebhook(ctx context.Context, roomIdOrName string, webhook *Webhook) (*PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsCreateRoomWebhook); err != nil {
+		return nil, err
+	}
+
+	var u, err = getRoomResourcePath(roomIdOrName, roomWebhookRoute)
+	if err != nil {
+		return nil, err
+	}
+
+	if webhook == nil || webhook.Key == "" {
+		return nil, emptyParam
+	}
+	if err := webhook.Validate(); err != nil {
+		return nil, err
+	}
+
+	u = strings.Join([]string{u, webhook.Key}, "/")
+	req, err := s.client.Put(u, webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// Gets the webhook registered with the given key.
+//
+// Authentication required, with scope admin_room.
+// Accessible by group clients, room clients, users.
+func (s *RoomsService) GetRoomWebhook(ctx context.Context, roomIdOrName string, key string) (*Webhook, *PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsGetRoomWebhook); err != nil {
+		return nil, nil, err
+	}
+
+	var u, err = getRoomResourcePath(roomIdOrName, roomWebhookRoute)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if key == "" {
+		return nil, nil, emptyParam
+	}
+
+	u = strings.Join([]string{u, key}, "/")
+	req, err := s.client.Get(u)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var webhook *Webhook
+	resp, err := s.client.Do(ctx, req, &webhook)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return webhook, resp, nil
+}
+
+// Gets all webhooks for this room.
+//
+// Authentication required, with scope admin_room.
+// Accessible by group clients, room clients, users.
+func (s *RoomsService) ListRoomWebhooks(ctx context.Context, roomIdOrName string, opt *ListOptions) ([]*Webhook, *PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsListRoomWebhooks); err != nil {
+		return nil, nil, err
+	}
+
+	var u, err = getRoomResourcePath(roomIdOrName, listRoomWebhooksRoute)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	opts, err := addUrlOptions(u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.Get(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var webhooks *webhooksListResponse
+	resp, err := s.client.Do(ctx, req, &webhooks)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return webhooks.Items, resp, nil
+}
+
+// Deletes the webhook registered with the given key. Deleting a webhook that
+// doesn't exist succeeds, with the 404 response HipChat returned, so cleanups
+// can be repeated.
+//
+// Authentication required, with scope admin_room.
+// Accessible by group clients, room clients, users.
+func (s *RoomsService) DeleteRoomWebhook(ctx context.Context, roomIdOrName string, key string) (*PaginatedResponse, error) {
+	if err := s.client.checkScope(opRoomsDeleteRoomWebhook); err != nil {
+		return nil, err
+	}
+
+	var u, err = getRoomResourcePath(roomIdOrName, roomWebhookRoute)
+	if err != nil {
+		return nil, err
+	}
+
+	if key == "" {
+		return nil, emptyParam
+	}
+
+	u = strings.Join([]string{u, key}, "/")
+	req, err := s.client.Delete(u)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil && !IsNotFound(err) {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// Creates a new Notification Object
+func NewNotification(message string) *Notification {
+	n := &Notification{}
+	n.Message = message
+
+	// Notifications default to html formatted yellow messages.
+	n.MessageFormat = MessageFormatHtml
+	n.Color = ColorYellow
+
+	return n
+}
+
 // Creates a new Room Object
 func NewRoom(name string) *Room {
 	r := &Room{}
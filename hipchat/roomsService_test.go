@@ -6,7 +6,11 @@ import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"net/http"
+	"io"
 	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
 )
 
 func (suite *HipChatClientTestSuite) TestRoomsService_ListRooms() {
@@ -194,6 +198,81 @@ func (suite *HipChatClientTestSuite) TestRoomsService_ShareFile() {
 
 
 
+func (suite *HipChatClientTestSuite) TestRoomsService_ShareReader() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(shareFileRoute, "1")
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	png := "\x89PNG\r\n\x1a\n" + strings.Repeat("0", 1024)
+	var body string
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodPost)
+
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	testCases := []struct {
+		name      string
+		reader    io.Reader
+		fileName  string
+		mediaType string
+		want      string
+	}{
+		{"TestMediaType", strings.NewReader("a,b"), "report", "text/csv", "Content-Type: text/csv"},
+		{"TestExtension", strings.NewReader("%PDF-"), "report.pdf", "", "Content-Type: application/pdf"},
+		{"TestSniffing", strings.NewReader(png), "screenshot", "", "Content-Type: image/png"},
+		{"TestSniffingUnknownSize", io.MultiReader(strings.NewReader(png)), "screenshot", "", "Content-Type: image/png"},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			resp, err := suite.client.Rooms.ShareReader(context.Background(), "1", tc.reader, tc.fileName, tc.mediaType, "hello")
+			assert.Nil(err)
+			assert.Equal(http.StatusNoContent, resp.StatusCode)
+			assert.Contains(body, tc.want)
+			assert.Contains(body, fmt.Sprintf(`filename="%v"`, tc.fileName))
+			assert.Contains(body, "hello")
+		})
+	}
+
+	// Sniffed contents are sent in full
+	assert.Contains(body, png)
+}
+
+func (suite *HipChatClientTestSuite) TestRoomsService_ShareReaderTooLarge() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(shareFileRoute, "1")
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	calls := 0
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		io.Copy(ioutil.Discard, r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	f, err := ioutil.TempFile("", "")
+	if err != nil {
+		assert.FailNow("failed to create temp file")
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	f.Truncate(MaxFileSize + 1)
+
+	// The size of files is checked before uploading them
+	_, err = suite.client.Rooms.ShareFile(context.Background(), "1", f, "")
+	assert.Equal(&FileTooLargeError{Size: MaxFileSize + 1, Limit: MaxFileSize}, err)
+	assert.Equal(0, calls)
+
+	// Readers of unknown size fail once they exceed the limit
+	reader := io.LimitReader(zeroReader{}, MaxFileSize+10)
+	_, err = suite.client.Rooms.ShareReader(context.Background(), "1", reader, "build.log", "", "")
+	assert.IsType(&FileTooLargeError{}, err)
+	assert.Equal(int64(MaxFileSize), err.(*FileTooLargeError).Limit)
+	assert.True(err.(*FileTooLargeError).Size > MaxFileSize)
+}
+
 func (suite *HipChatClientTestSuite) TestRoomsService_GetRoomParticipants() {
 	assert := assert.New(suite.T())
 	route := fmt.Sprintf(getRoomParticipantsRoute, "1")
@@ -259,6 +338,156 @@ func (suite *HipChatClientTestSuite) TestRoomsService_SendRoomMessage() {
 	assert.Equal("123", m.Id)
 }
 
+func (suite *HipChatClientTestSuite) TestRoomsService_SendRoomNotification() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(sendRoomNotificationRoute, "1")
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	input := NewNotification("build passed")
+	input.Color = ColorGreen
+	input.From = "ci"
+	input.Notify = true
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodPost)
+
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(`{"from":"ci","message_format":"html","color":"green","notify":true,"message":"build passed"}`+"\n", string(body))
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	resp, err := suite.client.Rooms.SendRoomNotification(context.Background(), "1", input)
+	assert.Nil(err)
+	assert.Equal(http.StatusNoContent, resp.StatusCode)
+
+	_, err = suite.client.Rooms.SendRoomNotification(context.Background(), "1", nil)
+	assert.EqualError(err, emptyParam.Error())
+
+	input.Card = &Card{Style: CardStyleImage, Id: "1", Title: "image"}
+	_, err = suite.client.Rooms.SendRoomNotification(context.Background(), "1", input)
+	assert.EqualError(err, missingCardField(CardStyleImage, "thumbnail.url").Error())
+}
+
+func (suite *HipChatClientTestSuite) TestRoomsService_ViewRoomHistory() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(viewRoomHistoryRoute, "1")
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodGet)
+		assert.Equal("date=recent&include_deleted=true&max-results=10&reverse=false&timezone=UTC", r.URL.RawQuery)
+		fmt.Fprint(w, `{"items":[`+
+			`{"id":"a","type":"message","date":"2018-01-01T00:00:00Z","message":"hi","from":{"id":1,"name":"Theo"},"mentions":[{"id":2,"name":"Alex"}]},`+
+			`{"id":"b","type":"notification","message":"done","from":"ci","color":"green","message_format":"text","card":"{\"style\":\"link\",\"id\":\"1\",\"title\":\"Docs\",\"url\":\"http://docs.co\"}"},`+
+			`{"id":"c","type":"topic","message":"new topic","from":{"id":1,"name":"Theo"}},`+
+			`{"id":"d","type":"message","message":"file","from":{"id":1,"name":"Theo"},"file":{"name":"a.txt","size":3,"url":"http://f.co/a.txt"}}],`+
+			`"startIndex":0,"maxResults":10,"links":{"self":"self"}}`)
+	})
+
+	opt := &HistoryOptions{Date: "recent", Timezone: "UTC", IncludeDeleted: true}
+	opt.MaxResults = 10
+	history, resp, err := suite.client.Rooms.ViewRoomHistory(context.Background(), "1", opt)
+	assert.Nil(err)
+	assert.Equal(10, resp.MaxResults)
+
+	want := []*HistoryMessage{
+		{Id: "a", Type: HistoryMessageTypeMessage, Date: "2018-01-01T00:00:00Z", Message: "hi",
+			From: &UserListItem{Id: 1, Name: "Theo"}, Mentions: []*UserListItem{{Id: 2, Name: "Alex"}}},
+		{Id: "b", Type: HistoryMessageTypeNotification, Message: "done", FromName: "ci", Color: ColorGreen,
+			MessageFormat: MessageFormatText, Card: &Card{Style: CardStyleLink, Id: "1", Title: "Docs", Url: "http://docs.co"}},
+		{Id: "c", Type: HistoryMessageTypeTopic, Message: "new topic", From: &UserListItem{Id: 1, Name: "Theo"}},
+		{Id: "d", Type: HistoryMessageTypeMessage, Message: "file", From: &UserListItem{Id: 1, Name: "Theo"},
+			File: &MessageFile{Name: "a.txt", Size: 3, Url: "http://f.co/a.txt"}},
+	}
+	assert.Equal(want, history)
+}
+
+func (suite *HipChatClientTestSuite) TestRoomsService_ViewRecentRoomHistory() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(viewRecentRoomHistoryRoute, "1")
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodGet)
+		assert.Equal("max-results=5&not-before=a", r.URL.RawQuery)
+		fmt.Fprint(w, `{"items":[{"id":"a","type":"guest_access","from":{"id":1,"name":"Theo"}}]}`)
+	})
+
+	opt := &RecentHistoryOptions{NotBefore: "a", MaxResults: 5}
+	history, _, err := suite.client.Rooms.ViewRecentRoomHistory(context.Background(), "1", opt)
+	assert.Nil(err)
+
+	want := []*HistoryMessage{{Id: "a", Type: HistoryMessageTypeGuestAccess, From: &UserListItem{Id: 1, Name: "Theo"}}}
+	assert.Equal(want, history)
+}
+
+func (suite *HipChatClientTestSuite) TestHistoryMessage_roundTrip() {
+	assert := assert.New(suite.T())
+	messages := []*HistoryMessage{
+		{Id: "a", Type: HistoryMessageTypeMessage, Message: "hi", From: &UserListItem{Id: 1, Name: "Theo"}},
+		{Id: "b", Type: HistoryMessageTypeNotification, Message: "done", FromName: "ci", Color: ColorGreen,
+			Card: &Card{Style: CardStyleLink, Id: "1", Title: "Docs", Url: "http://docs.co"}},
+		{Id: "c", Type: HistoryMessageTypeTopic, Message: "new topic"},
+	}
+
+	for _, m := range messages {
+		data, err := json.Marshal(m)
+		assert.Nil(err)
+
+		decoded := new(HistoryMessage)
+		assert.Nil(json.Unmarshal(data, decoded))
+		assert.Equal(m, decoded, string(data))
+	}
+
+	data, _ := json.Marshal(messages[1])
+	assert.Contains(string(data), `"from":"ci"`)
+}
+
+func (suite *HipChatClientTestSuite) TestRoomsService_GetRoomMessage() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(viewRoomHistoryRoute, "1")
+	route = fmt.Sprintf("/%s/%s/%s", apiVersion2, route, "123")
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodGet)
+		assert.Equal("max-results=10&timezone=UTC", r.URL.RawQuery)
+		fmt.Fprint(w, `{"message":{"id":"123","type":"message","message":"hello","from":{"id":1,"name":"Theo"}},`+
+			`"items":[{"id":"122","type":"message","message":"hi","from":{"id":2,"name":"Alex"}},`+
+			`{"id":"123","type":"message","message":"hello","from":{"id":1,"name":"Theo"}},`+
+			`{"id":"124","type":"notification","message":"done","from":"ci"}]}`)
+	})
+
+	m, _, err := suite.client.Rooms.GetRoomMessage(context.Background(), "1", "123", &MessageOptions{MaxResults: 10, Timezone: "UTC"})
+	assert.Nil(err)
+
+	message := &HistoryMessage{Id: "123", Type: HistoryMessageTypeMessage, Message: "hello", From: &UserListItem{Id: 1, Name: "Theo"}}
+	want := &RoomMessageContext{
+		Message: message,
+		Context: []*HistoryMessage{
+			{Id: "122", Type: HistoryMessageTypeMessage, Message: "hi", From: &UserListItem{Id: 2, Name: "Alex"}},
+			message,
+			{Id: "124", Type: HistoryMessageTypeNotification, Message: "done", FromName: "ci"},
+		},
+	}
+	assert.Equal(want, m)
+}
+
+func (suite *HipChatClientTestSuite) TestRoomsService_DeleteRoomMessage() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(viewRoomHistoryRoute, "1")
+	route = fmt.Sprintf("/%s/%s/%s", apiVersion2, route, "123")
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodDelete)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	resp, err := suite.client.Rooms.DeleteRoomMessage(context.Background(), "1", "123")
+	assert.Nil(err)
+	assert.Equal(http.StatusNoContent, resp.StatusCode)
+}
+
 func (suite *HipChatClientTestSuite) TestRoomsService_InviteUser() {
 	assert := assert.New(suite.T())
 	route := fmt.Sprintf(inviteUserRoute, "1")
@@ -294,6 +523,51 @@ func (suite *HipChatClientTestSuite) TestRoomsService_GetRoomMembers() {
 	assert.Equal(want, members)
 }
 
+func (suite *HipChatClientTestSuite) TestRoomsService_GetRoomMembersWithOptions() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(getRoomMembersRoute, "1")
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodGet)
+		assert.Equal("max-results=1&start-index=1", r.URL.RawQuery)
+		fmt.Fprint(w, `{"items":[{"id":2,"name":"Alex"}],"startIndex":1,"maxResults":1,"links":{"self":"self","prev":"prev","next":"next"}}`)
+	})
+
+	members, resp, err := suite.client.Rooms.GetRoomMembers(context.Background(), "1", &ListOptions{StartIndex: 1, MaxResults: 1})
+	assert.Nil(err)
+	assert.Equal([]*UserListItem{{Id: int64(2), Name: "Alex"}}, members)
+	assert.Equal(&PaginationLinks{Next: "next", Prev: "prev", Self: "self"}, resp.Links)
+}
+
+func (suite *HipChatClientTestSuite) TestRoomsService_GetAllRoomMembers() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(getRoomMembersRoute, "1")
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodGet)
+		switch r.URL.Query().Get("start-index") {
+		case "":
+			fmt.Fprint(w, `{"items":[{"id":1,"name":"Theo"},{"id":2,"name":"Alex"}],"links":{"self":"self","next":"`+route+`?start-index=2&max-results=2"}}`)
+		case "2":
+			assert.Equal("2", r.URL.Query().Get("max-results"))
+			fmt.Fprint(w, `{"items":[{"id":3,"name":"Nick"}],"links":{"self":"self"}}`)
+		default:
+			assert.Fail("unexpected page requested")
+		}
+	})
+
+	members, err := suite.client.Rooms.GetAllRoomMembers(context.Background(), "1")
+	assert.Nil(err)
+
+	want := []*UserListItem{
+		{Id: int64(1), Name: "Theo"},
+		{Id: int64(2), Name: "Alex"},
+		{Id: int64(3), Name: "Nick"}}
+	assert.Equal(want, members)
+}
+
 func (suite *HipChatClientTestSuite) TestRoomsService_AddRoomMember() {
 	assert := assert.New(suite.T())
 	route := fmt.Sprintf(getRoomMembersRoute, "1")
@@ -350,9 +624,33 @@ func (suite *HipChatClientTestSuite) TestRoomsService_EmptyRoomParams() {
 	_, _, err = suite.client.Rooms.SendRoomMessage(context.Background(), "", "")
 	assert.EqualError(err, emptyParam.Error())
 
+	_, err = suite.client.Rooms.SendRoomNotification(context.Background(), "", nil)
+	assert.EqualError(err, emptyParam.Error())
+
+	_, _, err = suite.client.Rooms.ViewRoomHistory(context.Background(), "", nil)
+	assert.EqualError(err, emptyParam.Error())
+
+	_, _, err = suite.client.Rooms.ViewRecentRoomHistory(context.Background(), "", nil)
+	assert.EqualError(err, emptyParam.Error())
+
+	_, _, err = suite.client.Rooms.GetRoomMessage(context.Background(), "", "", nil)
+	assert.EqualError(err, emptyParam.Error())
+
+	_, _, err = suite.client.Rooms.GetRoomMessage(context.Background(), "1", "", nil)
+	assert.EqualError(err, emptyParam.Error())
+
+	_, err = suite.client.Rooms.DeleteRoomMessage(context.Background(), "", "")
+	assert.EqualError(err, emptyParam.Error())
+
+	_, err = suite.client.Rooms.DeleteRoomMessage(context.Background(), "1", "")
+	assert.EqualError(err, emptyParam.Error())
+
 	_, _, err = suite.client.Rooms.GetRoomMembers(context.Background(), "", nil)
 	assert.EqualError(err, emptyParam.Error())
 
+	_, err = suite.client.Rooms.GetAllRoomMembers(context.Background(), "")
+	assert.EqualError(err, emptyParam.Error())
+
 	_, err = suite.client.Rooms.AddRoomMember(context.Background(), "", "")
 	assert.EqualError(err, emptyParam.Error())
 
@@ -364,4 +662,120 @@ func (suite *HipChatClientTestSuite) TestRoomsService_EmptyRoomParams() {
 
 	_, err = suite.client.Rooms.ShareFile(context.Background(), "", nil, "")
 	assert.EqualError(err, emptyParam.Error())
+
+	_, err = suite.client.Rooms.ShareReader(context.Background(), "1", nil, "report.pdf", "", "")
+	assert.EqualError(err, emptyParam.Error())
+
+	_, err = suite.client.Rooms.ShareReader(context.Background(), "1", strings.NewReader("a"), "", "", "")
+	assert.EqualError(err, emptyParam.Error())
+}
+
+func (suite *HipChatClientTestSuite) TestRoomsService_CreateRoomWebhook() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(roomWebhookRoute, "1")
+	route = fmt.Sprintf("/%s/%s/%s", apiVersion2, route, "deploy")
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodPut)
+
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(`{"key":"deploy","url":"https://ci.co/hook","event":"pattern","pattern":"^/deploy","authentication":"jwt"}`, string(body))
+
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	webhook := NewWebhook("deploy", "https://ci.co/hook", WebhookEventPattern)
+	webhook.Pattern = "^/deploy"
+	webhook.Authentication = WebhookAuthenticationJwt
+
+	resp, err := suite.client.Rooms.CreateRoomWebhook(context.Background(), "1", webhook)
+	assert.Nil(err)
+	assert.Equal(http.StatusCreated, resp.StatusCode)
+}
+
+func (suite *HipChatClientTestSuite) TestRoomsService_CreateRoomWebhookInvalid() {
+	assert := assert.New(suite.T())
+	testCases := []struct {
+		name    string
+		webhook *Webhook
+		want    error
+	}{
+		{"TestNilWebhook", nil, emptyParam},
+		{"TestMissingKey", NewWebhook("", "https://ci.co/hook", WebhookEventRoomEnter), emptyParam},
+		{"TestUnknownEvent", NewWebhook("k", "https://ci.co/hook", "room_joined"), invalidWebhookEvent},
+		{"TestMissingUrl", NewWebhook("k", "", WebhookEventRoomExit), missingWebhookField(WebhookEventRoomExit, "url")},
+		{"TestMissingPattern", NewWebhook("k", "https://ci.co/hook", WebhookEventPattern), missingWebhookField(WebhookEventPattern, "pattern")},
+		{"TestUnknownAuthentication", &Webhook{Key: "k", Url: "https://ci.co/hook", Event: WebhookEventRoomMessage, Authentication: "basic"}, invalidWebhookAuthentication},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			_, err := suite.client.Rooms.CreateRoomWebhook(context.Background(), "1", tc.webhook)
+			assert.Equal(tc.want, err)
+		})
+	}
+}
+
+func (suite *HipChatClientTestSuite) TestRoomsService_GetRoomWebhook() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(roomWebhookRoute, "1")
+	route = fmt.Sprintf("/%s/%s/%s", apiVersion2, route, "deploy")
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodGet)
+		fmt.Fprint(w, `{"id":7,"key":"deploy","name":"Deploy","url":"https://ci.co/hook","event":"room_message",
+			"authentication":"none","creator":{"id":1,"name":"Theo"},"links":{"self":"https://api.hipchat.com/v2/room/1/webhook/7"}}`)
+	})
+
+	webhook, _, err := suite.client.Rooms.GetRoomWebhook(context.Background(), "1", "deploy")
+	assert.Nil(err)
+	assert.Equal(int64(7), webhook.Id)
+	assert.Equal("deploy", webhook.Key)
+	assert.Equal("Deploy", webhook.Name)
+	assert.Equal(WebhookEventRoomMessage, webhook.Event)
+	assert.Equal(WebhookAuthenticationNone, webhook.Authentication)
+	assert.Equal("Theo", webhook.Creator.Name)
+	assert.Equal("https://api.hipchat.com/v2/room/1/webhook/7", webhook.Links.Self)
+}
+
+func (suite *HipChatClientTestSuite) TestRoomsService_ListRoomWebhooks() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(listRoomWebhooksRoute, "1")
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodGet)
+		assert.Equal("10", r.URL.Query().Get("max-results"))
+		fmt.Fprint(w, `{"items":[{"id":1,"url":"https://ci.co/a","event":"room_enter"},{"id":2,"url":"https://ci.co/b","event":"room_exit"}]}`)
+	})
+
+	webhooks, _, err := suite.client.Rooms.ListRoomWebhooks(context.Background(), "1", &ListOptions{MaxResults: 10})
+	assert.Nil(err)
+
+	want := []*Webhook{
+		{Id: 1, Url: "https://ci.co/a", Event: WebhookEventRoomEnter},
+		{Id: 2, Url: "https://ci.co/b", Event: WebhookEventRoomExit}}
+	assert.Equal(want, webhooks)
+}
+
+func (suite *HipChatClientTestSuite) TestRoomsService_DeleteRoomWebhook() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(roomWebhookRoute, "1")
+	route = fmt.Sprintf("/%s/%s/%s", apiVersion2, route, "deploy")
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodDelete)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	resp, err := suite.client.Rooms.DeleteRoomWebhook(context.Background(), "1", "deploy")
+	assert.Nil(err)
+	assert.Equal(http.StatusNoContent, resp.StatusCode)
+
+	// Deleting an unknown webhook succeeds
+	resp, err = suite.client.Rooms.DeleteRoomWebhook(context.Background(), "1", "missing")
+	assert.Nil(err)
+	assert.Equal(http.StatusNotFound, resp.StatusCode)
+
+	_, err = suite.client.Rooms.DeleteRoomWebhook(context.Background(), "1", "")
+	assert.EqualError(err, emptyParam.Error())
 }
@@ -0,0 +1,208 @@
+package hipchat
+
+const (
+	// OAuth scopes granted to access tokens
+	ScopeAdminGroup       = "admin_group"
+	ScopeAdminRoom        = "admin_room"
+	ScopeImportData       = "import_data"
+	ScopeManageRooms      = "manage_rooms"
+	ScopeSendMessage      = "send_message"
+	ScopeSendNotification = "send_notification"
+	ScopeViewGroup        = "view_group"
+	ScopeViewMessages     = "view_messages"
+	ScopeViewRoom         = "view_room"
+)
+
+// Operation describes an API operation and the scopes allowing it.
+type Operation struct {
+	// The name of the operation as Service.Method, for example 'Rooms.CreateRoom'.
+	Name string
+
+	// The scopes allowing the operation. A token needs any one of them.
+	Scopes []string
+}
+
+// operationName names an operation as Service.Method.
+type operationName string
+
+// Names of the operations requiring a scope
+const (
+	opEmoticonsListEmoticons     operationName = "Emoticons.ListEmoticons"
+	opEmoticonsGetEmoticon       operationName = "Emoticons.GetEmoticon"
+	opEmoticonsListRoomEmoticons operationName = "Emoticons.ListRoomEmoticons"
+
+	opRoomsListRooms             operationName = "Rooms.ListRooms"
+	opRoomsGetRoom               operationName = "Rooms.GetRoom"
+	opRoomsUpdateRoom            operationName = "Rooms.UpdateRoom"
+	opRoomsDeleteRoom            operationName = "Rooms.DeleteRoom"
+	opRoomsCreateRoom            operationName = "Rooms.CreateRoom"
+	opRoomsSetRoomTopic          operationName = "Rooms.SetRoomTopic"
+	opRoomsGetRoomStatistics     operationName = "Rooms.GetRoomStatistics"
+	opRoomsShareLinkWithRoom     operationName = "Rooms.ShareLinkWithRoom"
+	opRoomsGetRoomParticipants   operationName = "Rooms.GetRoomParticipants"
+	opRoomsReplyToRoomMessage    operationName = "Rooms.ReplyToRoomMessage"
+	opRoomsInviteUser            operationName = "Rooms.InviteUser"
+	opRoomsSendRoomMessage       operationName = "Rooms.SendRoomMessage"
+	opRoomsSendRoomNotification  operationName = "Rooms.SendRoomNotification"
+	opRoomsViewRoomHistory       operationName = "Rooms.ViewRoomHistory"
+	opRoomsViewRecentRoomHistory operationName = "Rooms.ViewRecentRoomHistory"
+	opRoomsGetRoomMessage        operationName = "Rooms.GetRoomMessage"
+	opRoomsDeleteRoomMessage     operationName = "Rooms.DeleteRoomMessage"
+	opRoomsGetRoomMembers        operationName = "Rooms.GetRoomMembers"
+	opRoomsAddRoomMember         operationName = "Rooms.AddRoomMember"
+	opRoomsRemoveRoomMember      operationName = "Rooms.RemoveRoomMember"
+	opRoomsShareFile             operationName = "Rooms.ShareFile"
+	opRoomsShareReader           operationName = "Rooms.ShareReader"
+	opRoomsCreateRoomWebhook     operationName = "Rooms.CreateRoomWebhook"
+	opRoomsGetRoomWebhook        operationName = "Rooms.GetRoomWebhook"
+	opRoomsListRoomWebhooks      operationName = "Rooms.ListRoomWebhooks"
+	opRoomsDeleteRoomWebhook     operationName = "Rooms.DeleteRoomWebhook"
+
+	opUsersListUsers                    operationName = "Users.ListUsers"
+	opUsersGetUser                      operationName = "Users.GetUser"
+	opUsersCreateUser                   operationName = "Users.CreateUser"
+	opUsersUpdateUser                   operationName = "Users.UpdateUser"
+	opUsersDeleteUser                   operationName = "Users.DeleteUser"
+	opUsersRestoreUser                  operationName = "Users.RestoreUser"
+	opUsersSendPrivateMessage           operationName = "Users.SendPrivateMessage"
+	opUsersViewPrivateChatHistory       operationName = "Users.ViewPrivateChatHistory"
+	opUsersViewRecentPrivateChatHistory operationName = "Users.ViewRecentPrivateChatHistory"
+	opUsersShareLinkWithUser            operationName = "Users.ShareLinkWithUser"
+	opUsersShareFileWithUser            operationName = "Users.ShareFileWithUser"
+	opUsersGetPhoto                     operationName = "Users.GetPhoto"
+	opUsersUpdatePhoto                  operationName = "Users.UpdatePhoto"
+	opUsersDeletePhoto                  operationName = "Users.DeletePhoto"
+)
+
+// operationScopes lists the scopes allowing an operation.
+type operationScopes struct {
+	name   operationName
+	scopes []string
+}
+
+// operations lists the operations of the services that require a scope.
+var operations = []operationScopes{
+	{opEmoticonsListEmoticons, []string{ScopeViewGroup}},
+	{opEmoticonsGetEmoticon, []string{ScopeViewGroup}},
+	{opEmoticonsListRoomEmoticons, []string{ScopeViewRoom}},
+
+	{opRoomsListRooms, []string{ScopeViewGroup, ScopeViewRoom}},
+	{opRoomsGetRoom, []string{ScopeViewGroup, ScopeViewRoom}},
+	{opRoomsUpdateRoom, []string{ScopeAdminRoom}},
+	{opRoomsDeleteRoom, []string{ScopeManageRooms}},
+	{opRoomsCreateRoom, []string{ScopeManageRooms}},
+	{opRoomsSetRoomTopic, []string{ScopeAdminRoom}},
+	{opRoomsGetRoomStatistics, []string{ScopeViewGroup, ScopeViewRoom}},
+	{opRoomsShareLinkWithRoom, []string{ScopeSendMessage}},
+	{opRoomsGetRoomParticipants, []string{ScopeViewRoom}},
+	{opRoomsReplyToRoomMessage, []string{ScopeSendMessage}},
+	{opRoomsInviteUser, []string{ScopeAdminRoom}},
+	{opRoomsSendRoomMessage, []string{ScopeSendMessage}},
+	{opRoomsSendRoomNotification, []string{ScopeSendNotification}},
+	{opRoomsViewRoomHistory, []string{ScopeViewGroup, ScopeViewMessages}},
+	{opRoomsViewRecentRoomHistory, []string{ScopeViewGroup, ScopeViewMessages}},
+	{opRoomsGetRoomMessage, []string{ScopeViewGroup, ScopeViewMessages}},
+	{opRoomsDeleteRoomMessage, []string{ScopeAdminRoom}},
+	{opRoomsGetRoomMembers, []string{ScopeViewRoom}},
+	{opRoomsAddRoomMember, []string{ScopeAdminRoom}},
+	{opRoomsRemoveRoomMember, []string{ScopeAdminRoom}},
+	{opRoomsShareFile, []string{ScopeSendMessage}},
+	{opRoomsShareReader, []string{ScopeSendMessage}},
+	{opRoomsCreateRoomWebhook, []string{ScopeAdminRoom}},
+	{opRoomsGetRoomWebhook, []string{ScopeAdminRoom}},
+	{opRoomsListRoomWebhooks, []string{ScopeAdminRoom}},
+	{opRoomsDeleteRoomWebhook, []string{ScopeAdminRoom}},
+
+	{opUsersListUsers, []string{ScopeAdminGroup, ScopeViewGroup}},
+	{opUsersGetUser, []string{ScopeViewGroup}},
+	{opUsersCreateUser, []string{ScopeAdminGroup}},
+	{opUsersUpdateUser, []string{ScopeAdminGroup}},
+	{opUsersDeleteUser, []string{ScopeAdminGroup}},
+	{opUsersRestoreUser, []string{ScopeAdminGroup}},
+	{opUsersSendPrivateMessage, []string{ScopeSendMessage}},
+	{opUsersViewPrivateChatHistory, []string{ScopeViewMessages}},
+	{opUsersViewRecentPrivateChatHistory, []string{ScopeViewMessages}},
+	{opUsersShareLinkWithUser, []string{ScopeSendMessage}},
+	{opUsersShareFileWithUser, []string{ScopeSendMessage}},
+	{opUsersGetPhoto, []string{ScopeViewGroup}},
+	{opUsersUpdatePhoto, []string{ScopeAdminGroup}},
+	{opUsersDeletePhoto, []string{ScopeAdminGroup}},
+}
+
+// Operations returns the operations requiring a scope, along with the scopes
+// allowing them.
+func Operations() []Operation {
+	return AllowedOperations()
+}
+
+// AllowedOperations returns the operations a token granted the given scopes
+// can perform. It returns every operation when no scopes are given.
+func AllowedOperations(scopes ...string) []Operation {
+	var allowed []Operation
+	for _, op := range operations {
+		if len(scopes) == 0 || op.allowedBy(scopes) {
+			allowed = append(allowed, Operation{string(op.name), append([]string(nil), op.scopes...)})
+		}
+	}
+
+	return allowed
+}
+
+func (op operationScopes) allowedBy(scopes []string) bool {
+	for _, required := range op.scopes {
+		for _, scope := range scopes {
+			if scope == required {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// SetTokenScopes sets the scopes granted to the token the client is
+// authenticated with. Calls to operations the token can't perform then fail
+// with an InsufficientScopeError before being sent. Calling it with no scopes
+// turns the checks off.
+func (c *Client) SetTokenScopes(scopes ...string) {
+	c.scopesMu.Lock()
+	defer c.scopesMu.Unlock()
+	c.scopes = append([]string(nil), scopes...)
+}
+
+// TokenScopes returns the scopes set with SetTokenScopes.
+func (c *Client) TokenScopes() []string {
+	c.scopesMu.RLock()
+	defer c.scopesMu.RUnlock()
+	return append([]string(nil), c.scopes...)
+}
+
+// AllowedOperations returns the operations the token of the client can
+// perform, according to the scopes set with SetTokenScopes. It returns every
+// operation when they are unknown.
+func (c *Client) AllowedOperations() []Operation {
+	return AllowedOperations(c.TokenScopes()...)
+}
+
+// checkScope checks that the token of the client can perform the named
+// operation, when the scopes of the token are known. Operations missing from
+// the scope table always fail, so they can't silently skip the check.
+func (c *Client) checkScope(name operationName) error {
+	for _, op := range operations {
+		if op.name != name {
+			continue
+		}
+
+		scopes := c.TokenScopes()
+		if len(scopes) == 0 || op.allowedBy(scopes) {
+			return nil
+		}
+		return &InsufficientScopeError{
+			Operation: string(op.name),
+			Required:  append([]string(nil), op.scopes...),
+			Granted:   scopes,
+		}
+	}
+
+	return unknownOperation(name)
+}
@@ -0,0 +1,189 @@
+package hipchat
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+func (suite *HipChatClientTestSuite) TestClient_SetTokenScopes() {
+	assert := assert.New(suite.T())
+
+	calls := 0
+	suite.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	suite.client.SetTokenScopes(ScopeViewRoom, ScopeSendNotification)
+	assert.Equal([]string{ScopeViewRoom, ScopeSendNotification}, suite.client.TokenScopes())
+
+	_, _, err := suite.client.Rooms.CreateRoom(context.Background(), NewRoom("Ops"))
+	assert.Equal(&InsufficientScopeError{
+		Operation: "Rooms.CreateRoom",
+		Required:  []string{ScopeManageRooms},
+		Granted:   []string{ScopeViewRoom, ScopeSendNotification},
+	}, err)
+	assert.EqualError(err, "insufficient_scope: Rooms.CreateRoom requires one of manage_rooms, token has view_room, send_notification")
+
+	_, _, err = suite.client.Users.ListUsers(context.Background(), nil)
+	assert.IsType(&InsufficientScopeError{}, err)
+	assert.Equal(0, calls)
+
+	// Any one of the scopes of an operation allows it
+	_, err = suite.client.Rooms.SendRoomNotification(context.Background(), "1", NewNotification("hello"))
+	assert.Nil(err)
+	assert.Equal(1, calls)
+
+	// The checks are off when the scopes are unknown
+	suite.client.SetTokenScopes()
+	_, err = suite.client.Rooms.DeleteRoom(context.Background(), "1")
+	assert.Nil(err)
+	assert.Equal(2, calls)
+}
+
+func (suite *HipChatClientTestSuite) TestClient_AllowedOperations() {
+	assert := assert.New(suite.T())
+
+	assert.Equal(Operations(), suite.client.AllowedOperations())
+
+	suite.client.SetTokenScopes(ScopeSendNotification)
+	assert.Equal([]Operation{{"Rooms.SendRoomNotification", []string{ScopeSendNotification}}}, suite.client.AllowedOperations())
+
+	var names []string
+	for _, op := range AllowedOperations(ScopeViewMessages) {
+		names = append(names, op.Name)
+	}
+	want := []string{
+		"Rooms.ViewRoomHistory",
+		"Rooms.ViewRecentRoomHistory",
+		"Rooms.GetRoomMessage",
+		"Users.ViewPrivateChatHistory",
+		"Users.ViewRecentPrivateChatHistory",
+	}
+	assert.Equal(want, names)
+
+	// The returned operations can't alter the scope table
+	ops := Operations()
+	ops[0].Scopes[0] = ScopeAdminGroup
+	assert.Equal(ScopeViewGroup, Operations()[0].Scopes[0])
+}
+
+// TestOperations_matchDocs checks that the scope table matches the scopes
+// documented on the service methods.
+func (suite *HipChatClientTestSuite) TestOperations_matchDocs() {
+	assert := assert.New(suite.T())
+
+	documented := make(map[string][]string)
+	scopeDoc := regexp.MustCompile(`with scope ([a-z_]+(?: or [a-z_]+)*)\.`)
+	files, _ := filepath.Glob("*Service.go")
+	for _, f := range files {
+		file, err := parser.ParseFile(token.NewFileSet(), f, nil, parser.ParseComments)
+		assert.Nil(err)
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || fn.Doc == nil {
+				continue
+			}
+			m := scopeDoc.FindStringSubmatch(fn.Doc.Text())
+			if m == nil {
+				continue
+			}
+			recv := fn.Recv.List[0].Type.(*ast.StarExpr).X.(*ast.Ident).Name
+			documented[strings.TrimSuffix(recv, "Service")+"."+fn.Name.Name] = strings.Split(m[1], " or ")
+		}
+	}
+
+	for _, op := range Operations() {
+		assert.Equal(documented[op.Name], op.Scopes, op.Name)
+		delete(documented, op.Name)
+	}
+
+	// Methods paging through other operations are checked by them
+	delete(documented, "Rooms.ListAllRooms")
+	delete(documented, "Rooms.GetAllRoomMembers")
+	assert.Empty(documented)
+}
+
+// TestOperations_coverServices checks that every exported method of the
+// services requiring a scope is in the scope table, and checks the scope of
+// its own operation.
+func (suite *HipChatClientTestSuite) TestOperations_coverServices() {
+	assert := assert.New(suite.T())
+
+	known := make(map[string]bool)
+	for _, op := range Operations() {
+		known[op.Name] = true
+	}
+
+	// Methods paging through other operations are checked by them
+	paging := map[string]bool{"Rooms.ListAllRooms": true, "Rooms.GetAllRoomMembers": true}
+
+	checked := make(map[string]string)
+	files, _ := filepath.Glob("*Service.go")
+	for _, f := range files {
+		file, err := parser.ParseFile(token.NewFileSet(), f, nil, 0)
+		assert.Nil(err)
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || !fn.Name.IsExported() {
+				continue
+			}
+			recv := fn.Recv.List[0].Type.(*ast.StarExpr).X.(*ast.Ident).Name
+			name := strings.TrimSuffix(recv, "Service") + "." + fn.Name.Name
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "checkScope" {
+					checked[name] = string(operationNames[call.Args[0].(*ast.Ident).Name])
+				}
+				return true
+			})
+		}
+	}
+
+	for _, svc := range []interface{}{suite.client.Rooms, suite.client.Users, suite.client.Emoticons} {
+		t := reflect.TypeOf(svc)
+		prefix := strings.TrimSuffix(t.Elem().Name(), "Service")
+		for i := 0; i < t.NumMethod(); i++ {
+			name := prefix + "." + t.Method(i).Name
+			if paging[name] {
+				continue
+			}
+			assert.True(known[name], "%v is missing from the scope table", name)
+			assert.Equal(name, checked[name], "%v doesn't check its scope", name)
+		}
+	}
+}
+
+// operationNames maps the names of the operation constants to their values.
+var operationNames = func() map[string]operationName {
+	names := make(map[string]operationName)
+	for _, op := range operations {
+		names["op"+strings.Replace(string(op.name), ".", "", 1)] = op.name
+	}
+	return names
+}()
+
+func (suite *HipChatClientTestSuite) TestClient_checkScopeUnknown() {
+	assert := assert.New(suite.T())
+
+	assert.Nil(suite.client.checkScope(opRoomsGetRoom))
+	assert.EqualError(suite.client.checkScope("Rooms.GetRoomz"),
+		"unknown_operation: Rooms.GetRoomz has no entry in the scope table")
+
+	suite.client.SetTokenScopes(ScopeViewRoom)
+	assert.Nil(suite.client.checkScope(opRoomsGetRoom))
+	assert.Error(suite.client.checkScope("Rooms.GetRoomz"))
+}
@@ -1,5 +1,43 @@
 package hipchat
 
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+const (
+	// User photo sizes
+	PhotoSizeSmall = "small"
+	PhotoSizeBig   = "big"
+
+	// User presence show values
+	PresenceShowAway = "away"
+	PresenceShowChat = "chat"
+	PresenceShowDnd  = "dnd"
+	PresenceShowXa   = "xa"
+
+	listUsersRoute   = "user"
+	getUserRoute     = "user/%v"
+	restoreUserRoute = "user/%v/restore"
+
+	sendPrivateMessageRoute           = "user/%v/message"
+	viewPrivateChatHistoryRoute       = "user/%v/history"
+	viewRecentPrivateChatHistoryRoute = "user/%v/history/latest"
+	shareFileWithUserRoute            = "user/%v/share/file"
+	shareLinkWithUserRoute            = "user/%v/share/link"
+
+	userPhotoRoute    = "user/%v/photo"
+	getUserPhotoRoute = "user/%v/photo/%v"
+)
+
+// UsersService handles communication with the user related
+// methods of the HipChat API.
+type UsersService service
+
 // UserListItem represents a HipChat User list item
 type UserListItem struct {
 	// The user Id.
@@ -23,6 +61,612 @@ type UserLinks struct {
 	Self string `json:"self"`
 }
 
+// User represents a HipChat User
+type User struct {
+	UserListItem
+
+	// XMPP/Jabber Id of the user.
+	XmppJid string `json:"xmpp_jid,omitempty"`
+
+	// Whether the user is deleted.
+	IsDeleted bool `json:"is_deleted"`
+
+	// Time the user was last active in ISO 8601 format UTC.
+	// May be null.
+	LastActive string `json:"last_active,omitempty"`
+
+	// User's title.
+	Title string `json:"title"`
+
+	// User's current presence.
+	// May be null.
+	Presence *UserPresence `json:"presence,omitempty"`
+
+	// Time the user was created in ISO 8601 format UTC.
+	Created string `json:"created,omitempty"`
+
+	// The roles of the user, for example owner, admin and user.
+	Roles []string `json:"roles,omitempty"`
+
+	// Whether or not this user is an admin.
+	IsGroupAdmin bool `json:"is_group_admin"`
+
+	// User's timezone. Must be a supported timezone.
+	Timezone string `json:"timezone,omitempty"`
+
+	// Whether or not this user is a guest or registered user.
+	IsGuest bool `json:"is_guest"`
+
+	// User's email.
+	// May be null.
+	Email string `json:"email,omitempty"`
+
+	// URL to user's photo. 125px on the longest side.
+	// May be null.
+	PhotoUrl string `json:"photo_url,omitempty"`
+
+	// The password of a user created without one, randomly generated.
+	// Only returned by UsersService.CreateUser.
+	Password string `json:"password,omitempty"`
+}
+
+// CreateUserRequest represents the attributes of a HipChat User to create
+type CreateUserRequest struct {
+	// User's full name. Valid length range: 1 - 50.
+	Name string `json:"name"`
+
+	// The roles of the user, for example owner, admin and user.
+	Roles []string `json:"roles,omitempty"`
+
+	// User's title.
+	Title string `json:"title,omitempty"`
+
+	// User's @mention name without the @.
+	MentionName string `json:"mention_name,omitempty"`
+
+	// Whether or not this user is an admin.
+	IsGroupAdmin bool `json:"is_group_admin,omitempty"`
+
+	// User's timezone. Must be a supported timezone.
+	//
+	// Defaults to 'UTC'.
+	Timezone string `json:"timezone,omitempty"`
+
+	// User's password. If not provided, a randomly generated password will be returned.
+	Password string `json:"password,omitempty"`
+
+	// User's email.
+	Email string `json:"email"`
+}
+
+// UpdateUserRequest represents the attributes of a HipChat User to update.
+// Attributes left empty are reset to their default.
+type UpdateUserRequest struct {
+	CreateUserRequest
+
+	// User's current presence.
+	Presence *UserPresenceRequest `json:"presence,omitempty"`
+}
+
+// UserPresenceRequest represents the presence to set on a HipChat User
+type UserPresenceRequest struct {
+	// A user's status message.
+	Status string `json:"status,omitempty"`
+
+	// Current presence.
+	// Valid values: away, chat, dnd, xa.
+	Show string `json:"show,omitempty"`
+}
+
+// UserPresence represents the presence of a HipChat User
+type UserPresence struct {
+	// A user's status message.
+	Status string `json:"status,omitempty"`
+
+	// The number of seconds a user has been idle. Only set if the user is idle.
+	Idle int64 `json:"idle,omitempty"`
+
+	// Current presence.
+	// Valid values: away, chat, dnd, xa.
+	Show string `json:"show,omitempty"`
+
+	// Client the user is connected with.
+	Client *struct {
+		// The type of client.
+		Type string `json:"type"`
+
+		// The version of the client.
+		Version string `json:"version"`
+	} `json:"client,omitempty"`
+
+	// Whether the user is connected or not.
+	IsOnline bool `json:"is_online"`
+}
+
+// PrivateMessage represents a HipChat one-on-one message
+type PrivateMessage struct {
+	// The message body. Valid length range: 1 - 10000.
+	Message string `json:"message"`
+
+	// Whether this message should trigger a user notification (change the tab color, play a sound,
+	// notify mobile phones, etc). Each recipient's notification preferences are taken into account.
+	//
+	// Defaults to 'false'.
+	Notify bool `json:"notify,omitempty"`
+
+	// Determines how the message is treated by HipChat's servers and rendered inside HipChat applications.
+	// Valid values: html, text.
+	//
+	// Defaults to 'text'.
+	MessageFormat string `json:"message_format,omitempty"`
+}
+
+// UsersListOptions specifies the optional parameters to the
+// UsersService.ListUsers
+type UsersListOptions struct {
+	// Include active guest users in response.
+	IncludeGuests bool `url:"include-guests,omitempty"`
+
+	// Include deleted users in response.
+	IncludeDeleted bool `url:"include-deleted,omitempty"`
+	ListOptions
+}
+
+// List all users in the group.
+//
+// Authentication required, with scope admin_group or view_group.
+// Accessible by group clients, users.
+func (s *UsersService) ListUsers(ctx context.Context, opt *UsersListOptions) ([]*UserListItem, *PaginatedResponse, error) {
+	if err := s.client.checkScope(opUsersListUsers); err != nil {
+		return nil, nil, err
+	}
+
+	opts, err := addUrlOptions(listUsersRoute, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.Get(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var users *usersListResponse
+	resp, err := s.client.Do(ctx, req, &users)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return users.Items, resp, nil
+}
+
+// Get a user's details. The user can be identified by id, email address,
+// or @mention name (beginning with an '@').
+//
+// Authentication required, with scope view_group.
+// Accessible by group clients, users.
+func (s *UsersService) GetUser(ctx context.Context, userIdOrEmail string) (*User, *PaginatedResponse, error) {
+	if err := s.client.checkScope(opUsersGetUser); err != nil {
+		return nil, nil, err
+	}
+
+	var u, err = getUserResourcePath(userIdOrEmail, getUserRoute)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.Get(u)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user := new(User)
+	resp, err := s.client.Do(ctx, req, user)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return user, resp, nil
+}
+
+// Creates a new user.
+//
+// Authentication required, with scope admin_group.
+// Accessible by group clients, users.
+func (s *UsersService) CreateUser(ctx context.Context, user *CreateUserRequest) (*User, *PaginatedResponse, error) {
+	if err := s.client.checkScope(opUsersCreateUser); err != nil {
+		return nil, nil, err
+	}
+
+	if user == nil {
+		return nil, nil, emptyParam
+	}
+
+	req, err := s.client.Post(listUsersRoute, user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	created := new(User)
+	resp, err := s.client.Do(ctx, req, created)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	// Only the id, links and generated password are returned
+	created.Name = user.Name
+	created.MentionName = user.MentionName
+	created.Roles = user.Roles
+	created.Title = user.Title
+	created.IsGroupAdmin = user.IsGroupAdmin
+	created.Timezone = user.Timezone
+	created.Email = user.Email
+
+	return created, resp, nil
+}
+
+// Update a user.
+//
+// Authentication required, with scope admin_group.
+// Accessible by group clients, users.
+func (s *UsersService) UpdateUser(ctx context.Context, userIdOrEmail string, user *UpdateUserRequest) (*PaginatedResponse, error) {
+	if err := s.client.checkScope(opUsersUpdateUser); err != nil {
+		return nil, err
+	}
+
+	var u, err = getUserResourcePath(userIdOrEmail, getUserRoute)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, emptyParam
+	}
+
+	req, err := s.client.Put(u, user)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// Delete a user.
+//
+// Authentication required, with scope admin_group.
+// Accessible by group clients, users.
+func (s *UsersService) DeleteUser(ctx context.Context, userIdOrEmail string) (*PaginatedResponse, error) {
+	if err := s.client.checkScope(opUsersDeleteUser); err != nil {
+		return nil, err
+	}
+
+	var u, err = getUserResourcePath(userIdOrEmail, getUserRoute)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.client.Delete(u)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// Restore a deleted user.
+//
+// Authentication required, with scope admin_group.
+// Accessible by group clients, users.
+func (s *UsersService) RestoreUser(ctx context.Context, userIdOrEmail string) (*PaginatedResponse, error) {
+	if err := s.client.checkScope(opUsersRestoreUser); err != nil {
+		return nil, err
+	}
+
+	var u, err = getUserResourcePath(userIdOrEmail, restoreUserRoute)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.client.Put(u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// Sends a user a private message.
+//
+// Authentication required, with scope send_message.
+// Accessible by users.
+func (s *UsersService) SendPrivateMessage(ctx context.Context, userIdOrEmail string, message *PrivateMessage) (*PaginatedResponse, error) {
+	if err := s.client.checkScope(opUsersSendPrivateMessage); err != nil {
+		return nil, err
+	}
+
+	var u, err = getUserResourcePath(userIdOrEmail, sendPrivateMessageRoute)
+	if err != nil {
+		return nil, err
+	}
+
+	if message == nil || message.Message == "" {
+		return nil, emptyParam
+	}
+
+	req, err := s.client.Post(u, message)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// Fetch one-to-one chat history between the token's user and the given user.
+//
+// Authentication required, with scope view_messages.
+// Accessible by users.
+func (s *UsersService) ViewPrivateChatHistory(ctx context.Context, userIdOrEmail string, opt *HistoryOptions) ([]*HistoryMessage, *PaginatedResponse, error) {
+	if err := s.client.checkScope(opUsersViewPrivateChatHistory); err != nil {
+		return nil, nil, err
+	}
+
+	var u, err = getUserResourcePath(userIdOrEmail, viewPrivateChatHistoryRoute)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	opts, err := addUrlOptions(u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.Get(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var history *historyListResponse
+	resp, err := s.client.Do(ctx, req, &history)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return history.Items, resp, nil
+}
+
+// Fetch latest one-to-one chat history between the token's user and the given user.
+//
+// Authentication required, with scope view_messages.
+// Accessible by users.
+func (s *UsersService) ViewRecentPrivateChatHistory(ctx context.Context, userIdOrEmail string, opt *RecentHistoryOptions) ([]*HistoryMessage, *PaginatedResponse, error) {
+	if err := s.client.checkScope(opUsersViewRecentPrivateChatHistory); err != nil {
+		return nil, nil, err
+	}
+
+	var u, err = getUserResourcePath(userIdOrEmail, viewRecentPrivateChatHistoryRoute)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	opts, err := addUrlOptions(u, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.Get(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var history *historyListResponse
+	resp, err := s.client.Do(ctx, req, &history)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return history.Items, resp, nil
+}
+
+// Share a link with the user.
+//
+// Authentication required, with scope send_message.
+// Accessible by users.
+func (s *UsersService) ShareLinkWithUser(ctx context.Context, userIdOrEmail string, message string, link string) (*PaginatedResponse, error) {
+	if err := s.client.checkScope(opUsersShareLinkWithUser); err != nil {
+		return nil, err
+	}
+
+	var u, err = getUserResourcePath(userIdOrEmail, shareLinkWithUserRoute)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.client.Post(u, shareLinkBody{message, link})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// Share a file with the user.
+//
+// Format the request as multipart/related with a single part of content-type
+// application/json and a second part containing your file.
+//
+// Authentication required, with scope send_message.
+// Accessible by users.
+func (s *UsersService) ShareFileWithUser(ctx context.Context, userIdOrEmail string, file *os.File, message string) (*PaginatedResponse, error) {
+	if err := s.client.checkScope(opUsersShareFileWithUser); err != nil {
+		return nil, err
+	}
+
+	var u, err = getUserResourcePath(userIdOrEmail, shareFileWithUserRoute)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.client.newShareFileRequest(u, file, message)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// Gets a user's photo and writes the image to w.
+//
+// Authentication required, with scope view_group.
+// Accessible by group clients, users.
+func (s *UsersService) GetPhoto(ctx context.Context, userIdOrEmail string, size string, w io.Writer) (*PaginatedResponse, error) {
+	if err := s.client.checkScope(opUsersGetPhoto); err != nil {
+		return nil, err
+	}
+
+	if userIdOrEmail == "" || size == "" || w == nil {
+		return nil, emptyParam
+	}
+
+	if size != PhotoSizeSmall && size != PhotoSizeBig {
+		return nil, invalidPhotoSize
+	}
+
+	u := fmt.Sprintf(getUserPhotoRoute, userIdOrEmail, size)
+	req, err := s.client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(ctx, req, w)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// Update a user's photo. The image read from photo is base64 encoded before
+// it's sent.
+//
+// Authentication required, with scope admin_group.
+// Accessible by group clients, users.
+func (s *UsersService) UpdatePhoto(ctx context.Context, userIdOrEmail string, photo io.Reader, mediaType string) (*PaginatedResponse, error) {
+	if err := s.client.checkScope(opUsersUpdatePhoto); err != nil {
+		return nil, err
+	}
+
+	var u, err = getUserResourcePath(userIdOrEmail, userPhotoRoute)
+	if err != nil {
+		return nil, err
+	}
+
+	if photo == nil {
+		return nil, emptyParam
+	}
+
+	switch mediaType {
+	case "image/png", "image/jpeg", "image/gif":
+	default:
+		return nil, invalidPhotoMediaType
+	}
+
+	image, err := ioutil.ReadAll(photo)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.client.Put(u, photoBody{base64.StdEncoding.EncodeToString(image)})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// Delete a user's photo.
+//
+// Authentication required, with scope admin_group.
+// Accessible by group clients, users.
+func (s *UsersService) DeletePhoto(ctx context.Context, userIdOrEmail string) (*PaginatedResponse, error) {
+	if err := s.client.checkScope(opUsersDeletePhoto); err != nil {
+		return nil, err
+	}
+
+	var u, err = getUserResourcePath(userIdOrEmail, userPhotoRoute)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.client.Delete(u)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// Creates a new User Object
+func NewUser(name string, email string) *User {
+	u := &User{}
+	u.UserListItem = UserListItem{}
+	u.Name = name
+	u.Email = email
+
+	return u
+}
+
+func getUserResourcePath(userIdOrEmail string, route string) (string, error) {
+	if userIdOrEmail != "" {
+		return fmt.Sprintf(route, userIdOrEmail), nil
+	} else {
+		return "", emptyParam
+	}
+}
+
 type usersListResponse struct {
 	Items []*UserListItem `json:"items,omitempty"`
 }
+
+type photoBody struct {
+	Photo string `json:"photo"`
+}
@@ -0,0 +1,362 @@
+package hipchat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+)
+
+func (suite *HipChatClientTestSuite) TestUsersService_ListUsers() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf("/%s/%s", apiVersion2, listUsersRoute)
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodGet)
+		assert.Equal("include-deleted=true&include-guests=true", r.URL.RawQuery)
+		fmt.Fprint(w, `{"items":[{"id":1,"name":"Theo","mention_name":"theo"},{"id":2,"name":"Alex"}]}`)
+	})
+
+	opt := &UsersListOptions{IncludeGuests: true, IncludeDeleted: true}
+	users, _, err := suite.client.Users.ListUsers(context.Background(), opt)
+	assert.Nil(err)
+
+	want := []*UserListItem{
+		{Id: int64(1), Name: "Theo", MentionName: "theo"},
+		{Id: int64(2), Name: "Alex"}}
+	assert.Equal(want, users)
+}
+
+func (suite *HipChatClientTestSuite) TestUsersService_GetUser() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(getUserRoute, "@theo")
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodGet)
+		fmt.Fprint(w, `{"id":1,"name":"Theo","title":"Dev","email":"theo@example.com","is_group_admin":true,`+
+			`"timezone":"UTC","photo_url":"http://p.co/1.png","roles":["admin","user"],`+
+			`"presence":{"show":"chat","is_online":true}}`)
+	})
+
+	user, _, err := suite.client.Users.GetUser(context.Background(), "@theo")
+	assert.Nil(err)
+
+	want := NewUser("Theo", "theo@example.com")
+	want.Id = int64(1)
+	want.Title = "Dev"
+	want.IsGroupAdmin = true
+	want.Timezone = "UTC"
+	want.PhotoUrl = "http://p.co/1.png"
+	want.Roles = []string{"admin", "user"}
+	want.Presence = &UserPresence{Show: PresenceShowChat, IsOnline: true}
+	assert.Equal(want, user)
+}
+
+func (suite *HipChatClientTestSuite) TestUsersService_CreateUser() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf("/%s/%s", apiVersion2, listUsersRoute)
+
+	user := &CreateUserRequest{Name: "Theo", Email: "theo@example.com", Title: "Dev"}
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodPost)
+
+		// Read-only attributes aren't sent
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(`{"name":"Theo","email":"theo@example.com","title":"Dev"}`, string(body))
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":1,"password":"secret","links":{"self":"self"}}`)
+	})
+
+	v, resp, err := suite.client.Users.CreateUser(context.Background(), user)
+	want := NewUser("Theo", "theo@example.com")
+	want.Id = int64(1)
+	want.Title = "Dev"
+	want.Password = "secret"
+	want.Links = &UserLinks{Self: "self"}
+
+	assert.Nil(err)
+	assert.Equal(want, v)
+	assert.Equal(http.StatusCreated, resp.StatusCode)
+	assert.Equal(&CreateUserRequest{Name: "Theo", Email: "theo@example.com", Title: "Dev"}, user)
+}
+
+func (suite *HipChatClientTestSuite) TestUsersService_UpdateUser() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(getUserRoute, "1")
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodPut)
+
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(`{"name":"Theo","email":"theo@example.com","presence":{"show":"dnd"}}`, string(body))
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	user := &UpdateUserRequest{Presence: &UserPresenceRequest{Show: PresenceShowDnd}}
+	user.Name = "Theo"
+	user.Email = "theo@example.com"
+	resp, err := suite.client.Users.UpdateUser(context.Background(), "1", user)
+	assert.Nil(err)
+	assert.Equal(http.StatusNoContent, resp.StatusCode)
+}
+
+func (suite *HipChatClientTestSuite) TestUsersService_DeleteUser() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(getUserRoute, "1")
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodDelete)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	resp, err := suite.client.Users.DeleteUser(context.Background(), "1")
+	assert.Nil(err)
+	assert.Equal(http.StatusNoContent, resp.StatusCode)
+}
+
+func (suite *HipChatClientTestSuite) TestUsersService_RestoreUser() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(restoreUserRoute, "1")
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodPut)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	resp, err := suite.client.Users.RestoreUser(context.Background(), "1")
+	assert.Nil(err)
+	assert.Equal(http.StatusNoContent, resp.StatusCode)
+}
+
+func (suite *HipChatClientTestSuite) TestUsersService_SendPrivateMessage() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(sendPrivateMessageRoute, "1")
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	input := &PrivateMessage{Message: "hello", Notify: true, MessageFormat: MessageFormatText}
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodPost)
+
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(`{"message":"hello","notify":true,"message_format":"text"}`+"\n", string(body))
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	resp, err := suite.client.Users.SendPrivateMessage(context.Background(), "1", input)
+	assert.Nil(err)
+	assert.Equal(http.StatusNoContent, resp.StatusCode)
+}
+
+func (suite *HipChatClientTestSuite) TestUsersService_ViewPrivateChatHistory() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(viewPrivateChatHistoryRoute, "1")
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodGet)
+		assert.Equal("date=recent&reverse=true", r.URL.RawQuery)
+		fmt.Fprint(w, `{"items":[{"id":"a","type":"message","message":"hi","from":{"id":2,"name":"Alex"}}]}`)
+	})
+
+	history, _, err := suite.client.Users.ViewPrivateChatHistory(context.Background(), "1", &HistoryOptions{Date: "recent", Reverse: true})
+	assert.Nil(err)
+
+	want := []*HistoryMessage{{Id: "a", Type: HistoryMessageTypeMessage, Message: "hi", From: &UserListItem{Id: 2, Name: "Alex"}}}
+	assert.Equal(want, history)
+}
+
+func (suite *HipChatClientTestSuite) TestUsersService_ViewRecentPrivateChatHistory() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(viewRecentPrivateChatHistoryRoute, "1")
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodGet)
+		assert.Equal("not-before=a", r.URL.RawQuery)
+		fmt.Fprint(w, `{"items":[{"id":"b","type":"message","message":"hi","from":{"id":2,"name":"Alex"}}]}`)
+	})
+
+	history, _, err := suite.client.Users.ViewRecentPrivateChatHistory(context.Background(), "1", &RecentHistoryOptions{NotBefore: "a"})
+	assert.Nil(err)
+
+	want := []*HistoryMessage{{Id: "b", Type: HistoryMessageTypeMessage, Message: "hi", From: &UserListItem{Id: 2, Name: "Alex"}}}
+	assert.Equal(want, history)
+}
+
+func (suite *HipChatClientTestSuite) TestUsersService_ShareLinkWithUser() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(shareLinkWithUserRoute, "1")
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	input := shareLinkBody{"hello", "link"}
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodPost)
+
+		link := shareLinkBody{}
+		json.NewDecoder(r.Body).Decode(&link)
+		assert.Equal(input, link)
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	resp, err := suite.client.Users.ShareLinkWithUser(context.Background(), "1", input.Message, input.Link)
+	assert.Nil(err)
+	assert.Equal(http.StatusNoContent, resp.StatusCode)
+}
+
+func (suite *HipChatClientTestSuite) TestUsersService_ShareFileWithUser() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(shareFileWithUserRoute, "1")
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	message := "hello world"
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodPost)
+		assert.Contains(r.Header.Get("Content-Type"), "multipart/related")
+
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Contains(string(body), message)
+		assert.Contains(string(body), "file contents")
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	f, err := ioutil.TempFile("", "")
+	if err != nil {
+		assert.FailNow("failed to create temp file")
+	}
+	defer f.Close()
+	f.WriteString("file contents")
+	f.Seek(0, 0)
+
+	resp, err := suite.client.Users.ShareFileWithUser(context.Background(), "1", f, message)
+	assert.Nil(err)
+	assert.Equal(http.StatusNoContent, resp.StatusCode)
+
+	_, err = suite.client.Users.ShareFileWithUser(context.Background(), "1", nil, message)
+	assert.EqualError(err, emptyParam.Error())
+}
+
+func (suite *HipChatClientTestSuite) TestUsersService_GetPhoto() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(getUserPhotoRoute, "1", PhotoSizeBig)
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodGet)
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG image"))
+	})
+
+	buf := new(bytes.Buffer)
+	resp, err := suite.client.Users.GetPhoto(context.Background(), "1", PhotoSizeBig, buf)
+	assert.Nil(err)
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	assert.Equal("\x89PNG image", buf.String())
+
+	_, err = suite.client.Users.GetPhoto(context.Background(), "1", "huge", buf)
+	assert.EqualError(err, invalidPhotoSize.Error())
+}
+
+func (suite *HipChatClientTestSuite) TestUsersService_UpdatePhoto() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(userPhotoRoute, "1")
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodPut)
+
+		photo := photoBody{}
+		json.NewDecoder(r.Body).Decode(&photo)
+		assert.Equal(photoBody{"aW1hZ2U="}, photo)
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	resp, err := suite.client.Users.UpdatePhoto(context.Background(), "1", bytes.NewReader([]byte("image")), "image/png")
+	assert.Nil(err)
+	assert.Equal(http.StatusNoContent, resp.StatusCode)
+
+	_, err = suite.client.Users.UpdatePhoto(context.Background(), "1", bytes.NewReader([]byte("image")), "text/plain")
+	assert.EqualError(err, invalidPhotoMediaType.Error())
+}
+
+func (suite *HipChatClientTestSuite) TestUsersService_DeletePhoto() {
+	assert := assert.New(suite.T())
+	route := fmt.Sprintf(userPhotoRoute, "1")
+	route = fmt.Sprintf("/%s/%s", apiVersion2, route)
+
+	suite.mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(r.Method, http.MethodDelete)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	resp, err := suite.client.Users.DeletePhoto(context.Background(), "1")
+	assert.Nil(err)
+	assert.Equal(http.StatusNoContent, resp.StatusCode)
+}
+
+func (suite *HipChatClientTestSuite) TestUsersService_EmptyUserParams() {
+	assert := assert.New(suite.T())
+	_, _, err := suite.client.Users.GetUser(context.Background(), "")
+	assert.EqualError(err, emptyParam.Error())
+
+	_, err = suite.client.Users.UpdateUser(context.Background(), "", nil)
+	assert.EqualError(err, emptyParam.Error())
+
+	_, err = suite.client.Users.UpdateUser(context.Background(), "1", nil)
+	assert.EqualError(err, emptyParam.Error())
+
+	_, _, err = suite.client.Users.CreateUser(context.Background(), nil)
+	assert.EqualError(err, emptyParam.Error())
+
+	_, err = suite.client.Users.DeleteUser(context.Background(), "")
+	assert.EqualError(err, emptyParam.Error())
+
+	_, err = suite.client.Users.RestoreUser(context.Background(), "")
+	assert.EqualError(err, emptyParam.Error())
+
+	_, err = suite.client.Users.SendPrivateMessage(context.Background(), "", nil)
+	assert.EqualError(err, emptyParam.Error())
+
+	_, err = suite.client.Users.SendPrivateMessage(context.Background(), "1", &PrivateMessage{})
+	assert.EqualError(err, emptyParam.Error())
+
+	_, _, err = suite.client.Users.ViewPrivateChatHistory(context.Background(), "", nil)
+	assert.EqualError(err, emptyParam.Error())
+
+	_, _, err = suite.client.Users.ViewRecentPrivateChatHistory(context.Background(), "", nil)
+	assert.EqualError(err, emptyParam.Error())
+
+	_, err = suite.client.Users.ShareLinkWithUser(context.Background(), "", "", "")
+	assert.EqualError(err, emptyParam.Error())
+
+	_, err = suite.client.Users.ShareFileWithUser(context.Background(), "", nil, "")
+	assert.EqualError(err, emptyParam.Error())
+
+	_, err = suite.client.Users.GetPhoto(context.Background(), "", "", nil)
+	assert.EqualError(err, emptyParam.Error())
+
+	_, err = suite.client.Users.UpdatePhoto(context.Background(), "", nil, "")
+	assert.EqualError(err, emptyParam.Error())
+
+	_, err = suite.client.Users.UpdatePhoto(context.Background(), "1", nil, "image/png")
+	assert.EqualError(err, emptyParam.Error())
+
+	_, err = suite.client.Users.DeletePhoto(context.Background(), "")
+	assert.EqualError(err, emptyParam.Error())
+}
@@ -0,0 +1,97 @@
+package hipchat
+
+const (
+	// Webhook events
+	WebhookEventRoomMessage      = "room_message"
+	WebhookEventRoomNotification = "room_notification"
+	WebhookEventRoomEnter        = "room_enter"
+	WebhookEventRoomExit         = "room_exit"
+	WebhookEventRoomTopicChange  = "room_topic_change"
+	WebhookEventRoomArchived     = "room_archived"
+	WebhookEventRoomDeleted      = "room_deleted"
+	WebhookEventRoomFileUpload   = "room_file_upload"
+	WebhookEventPattern          = "pattern"
+	WebhookEventAuthentication   = "authentication"
+
+	// How HipChat authenticates the requests sent to a webhook
+	WebhookAuthenticationJwt  = "jwt"
+	WebhookAuthenticationNone = "none"
+)
+
+// Webhook represents a HipChat Room Webhook
+type Webhook struct {
+	// The id of the webhook. Set by HipChat.
+	Id int64 `json:"id,omitempty"`
+
+	// The unique key of the webhook within the room. Creating a webhook with an
+	// existing key replaces it.
+	// Valid length range: 1 - 40.
+	Key string `json:"key,omitempty"`
+
+	// The label for this webhook.
+	Name string `json:"name,omitempty"`
+
+	// The URL to send the webhook POST to.
+	Url string `json:"url"`
+
+	// The event to listen for.
+	// Valid values: room_message, room_notification, room_enter, room_exit, room_topic_change,
+	// room_archived, room_deleted, room_file_upload, pattern, authentication.
+	Event string `json:"event"`
+
+	// The regular expression pattern to match against messages. Only applicable
+	// for pattern events.
+	Pattern string `json:"pattern,omitempty"`
+
+	// The type of authentication to use when sending the webhook.
+	// Valid values: jwt, none.
+	//
+	// Defaults to 'none'.
+	Authentication string `json:"authentication,omitempty"`
+
+	// The user that created the webhook. Set by HipChat.
+	Creator *UserListItem `json:"creator,omitempty"`
+
+	// URLs to retrieve webhook information
+	Links *struct {
+		// The URL to use to retrieve the full webhook information
+		Self string `json:"self"`
+	} `json:"links,omitempty"`
+}
+
+// Creates a new Webhook Object
+func NewWebhook(key string, url string, event string) *Webhook {
+	return &Webhook{Key: key, Url: url, Event: event}
+}
+
+// Validate checks that the webhook listens for a known event and carries the
+// fields that event requires.
+func (w *Webhook) Validate() error {
+	switch w.Event {
+	case WebhookEventRoomMessage, WebhookEventRoomNotification, WebhookEventRoomEnter,
+		WebhookEventRoomExit, WebhookEventRoomTopicChange, WebhookEventRoomArchived,
+		WebhookEventRoomDeleted, WebhookEventRoomFileUpload, WebhookEventAuthentication:
+	case WebhookEventPattern:
+		if w.Pattern == "" {
+			return missingWebhookField(w.Event, "pattern")
+		}
+	default:
+		return invalidWebhookEvent
+	}
+
+	switch w.Authentication {
+	case "", WebhookAuthenticationJwt, WebhookAuthenticationNone:
+	default:
+		return invalidWebhookAuthentication
+	}
+
+	if w.Url == "" {
+		return missingWebhookField(w.Event, "url")
+	}
+
+	return nil
+}
+
+type webhooksListResponse struct {
+	Items []*Webhook `json:"items,omitempty"`
+}
@@ -0,0 +1,111 @@
+package webhook
+
+import "github.com/theodesp/go-hipchat/hipchat"
+
+// Event holds the attributes sent along with every webhook callback.
+type Event struct {
+	// The event that triggered the callback, for example 'room_message'.
+	Event string `json:"event"`
+
+	// The id of the OAuth client the webhook was registered with, if any.
+	OAuthClientId string `json:"oauth_client_id,omitempty"`
+
+	// The id of the webhook that triggered the callback.
+	WebhookId int64 `json:"webhook_id"`
+}
+
+// RoomMessageEvent is sent when a message is posted in a room. Webhooks
+// registered for the pattern event send it as well.
+type RoomMessageEvent struct {
+	Event `json:"-"`
+
+	// The message that was posted.
+	Message *hipchat.HistoryMessage `json:"message"`
+
+	// The room the message was posted in.
+	Room *hipchat.Room `json:"room"`
+}
+
+// RoomNotificationEvent is sent when a notification is posted in a room.
+type RoomNotificationEvent struct {
+	Event `json:"-"`
+
+	// The notification that was posted. Its FromName is the label of the
+	// integration that sent it.
+	Message *hipchat.HistoryMessage `json:"message"`
+
+	// The room the notification was posted in.
+	Room *hipchat.Room `json:"room"`
+}
+
+// RoomEnterEvent is sent when a user enters a room.
+type RoomEnterEvent struct {
+	Event `json:"-"`
+
+	// The room that was entered.
+	Room *hipchat.Room `json:"room"`
+
+	// The user that entered the room.
+	Sender *hipchat.UserListItem `json:"sender"`
+}
+
+// RoomExitEvent is sent when a user leaves a room.
+type RoomExitEvent struct {
+	Event `json:"-"`
+
+	// The room that was left.
+	Room *hipchat.Room `json:"room"`
+
+	// The user that left the room.
+	Sender *hipchat.UserListItem `json:"sender"`
+}
+
+// RoomTopicChangeEvent is sent when the topic of a room changes.
+type RoomTopicChangeEvent struct {
+	Event `json:"-"`
+
+	// The room whose topic changed.
+	Room *hipchat.Room `json:"room"`
+
+	// The user that changed the topic.
+	Sender *hipchat.UserListItem `json:"sender"`
+
+	// The new topic.
+	Topic string `json:"topic"`
+}
+
+// RoomArchivedEvent is sent when a room is archived.
+type RoomArchivedEvent struct {
+	Event `json:"-"`
+
+	// The room that was archived.
+	Room *hipchat.Room `json:"room"`
+
+	// The user that archived the room.
+	Sender *hipchat.UserListItem `json:"sender"`
+}
+
+// RoomDeletedEvent is sent when a room is deleted.
+type RoomDeletedEvent struct {
+	Event `json:"-"`
+
+	// The room that was deleted.
+	Room *hipchat.Room `json:"room"`
+
+	// The user that deleted the room.
+	Sender *hipchat.UserListItem `json:"sender"`
+}
+
+// RoomFileUploadEvent is sent when a file is uploaded to a room.
+type RoomFileUploadEvent struct {
+	Event `json:"-"`
+
+	// The file that was uploaded.
+	File *hipchat.MessageFile `json:"file"`
+
+	// The room the file was uploaded to.
+	Room *hipchat.Room `json:"room"`
+
+	// The user that uploaded the file.
+	Sender *hipchat.UserListItem `json:"sender"`
+}
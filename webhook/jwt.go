@@ -0,0 +1,285 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	jwtAlgorithm = "HS256"
+
+	// qsh value of tokens that aren't bound to a request
+	contextQueryHash = "context-qsh"
+)
+
+var missingToken = errors.New("missing_token: the request carries no JWT")
+var malformedToken = errors.New("malformed_token: the JWT can't be decoded")
+var invalidAlgorithm = errors.New("invalid_algorithm: the JWT must be signed with HS256")
+var unknownIssuer = errors.New("unknown_issuer: no secret is known for the JWT issuer")
+var invalidSignature = errors.New("invalid_signature: the JWT signature doesn't match")
+var expiredToken = errors.New("expired_token: the JWT has expired")
+var invalidQueryHash = errors.New("invalid_qsh: the JWT was issued for a different request")
+var missingQueryHash = errors.New("invalid_qsh: the JWT isn't bound to a request")
+
+// SecretStore looks up the shared secret of an installation, which HipChat
+// signs the callbacks of the installation with.
+type SecretStore interface {
+	// Secret returns the oauth secret of the installation with the given
+	// oauth id. It should return an error if there is none.
+	Secret(ctx context.Context, oauthId string) (string, error)
+}
+
+// The SecretStoreFunc type is an adapter to allow the use of ordinary
+// functions as secret stores.
+type SecretStoreFunc func(ctx context.Context, oauthId string) (string, error)
+
+// Secret calls f(ctx, oauthId).
+func (f SecretStoreFunc) Secret(ctx context.Context, oauthId string) (string, error) {
+	return f(ctx, oauthId)
+}
+
+// Claims are the claims of the JWT a request is signed with.
+type Claims struct {
+	// The oauth id of the installation that issued the token.
+	Issuer string `json:"iss"`
+
+	// The id of the user the request was made for, if any.
+	Subject string `json:"sub,omitempty"`
+
+	// The time the token was issued at in UNIX time.
+	IssuedAt int64 `json:"iat"`
+
+	// The time the token expires at in UNIX time.
+	ExpiresAt int64 `json:"exp"`
+
+	// The hash of the request the token was issued for, or 'context-qsh' for
+	// tokens of pages loaded in HipChat.
+	QueryHash string `json:"qsh,omitempty"`
+
+	// The context the request was made in, if any.
+	Context *ClaimsContext `json:"context,omitempty"`
+}
+
+// ClaimsContext is the context a signed request was made in.
+type ClaimsContext struct {
+	// The id of the room.
+	RoomId int64 `json:"room_id,omitempty"`
+
+	// The timezone of the user.
+	UserTz string `json:"user_tz,omitempty"`
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+}
+
+type contextKey struct{}
+
+// ClaimsFromContext returns the claims of a request verified by a Verifier.
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(*Claims)
+	return claims, ok
+}
+
+// Verifier authenticates the requests HipChat sends to webhooks and add-ons.
+//
+// Requests are signed with a JWT using HS256 and the oauth secret of the
+// installation, carried either in the Authorization header, as
+// 'Authorization: JWT <token>', or in the signed_request or jwt query
+// parameters.
+type Verifier struct {
+	// The store the secrets of installations are looked up in.
+	Secrets SecretStore
+
+	// The clock skew tolerated when checking the expiry of tokens.
+	Leeway time.Duration
+
+	// The path the handlers are mounted under, which is left out of the
+	// request path when checking the qsh claim.
+	BasePath string
+
+	// Whether to accept tokens whose qsh claim is 'context-qsh', which HipChat
+	// issues to the pages it loads, such as glances and dialogs. Such tokens
+	// aren't bound to a request and may be replayed against any endpoint, so
+	// only set it on the Verifier of the handlers serving those pages.
+	AllowContextQsh bool
+
+	now func() time.Time
+}
+
+// NewVerifier returns a Verifier looking up the secrets of installations in secrets.
+func NewVerifier(secrets SecretStore) *Verifier {
+	return &Verifier{Secrets: secrets}
+}
+
+// Middleware returns a handler calling next with the requests that pass
+// verification, with their claims available through ClaimsFromContext.
+// Other requests are rejected with 401 Unauthorized.
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, err := v.Verify(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", "JWT")
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), contextKey{}, claims)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Verify checks the signature, issuer and expiry of the JWT r is signed with,
+// and that its qsh claim matches the hash of r. Tokens with a 'context-qsh'
+// claim are only accepted when AllowContextQsh is set.
+func (v *Verifier) Verify(r *http.Request) (*Claims, error) {
+	token := requestToken(r)
+	if token == "" {
+		return nil, missingToken
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, malformedToken
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if header.Algorithm != jwtAlgorithm {
+		return nil, invalidAlgorithm
+	}
+
+	// The claims are read before verifying the signature to find the issuer
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if claims.Issuer == "" {
+		return nil, unknownIssuer
+	}
+
+	secret, err := v.Secrets.Secret(r.Context(), claims.Issuer)
+	if err != nil || secret == "" {
+		return nil, unknownIssuer
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, malformedToken
+	}
+	if !hmac.Equal(signature, sign(parts[0]+"."+parts[1], secret)) {
+		return nil, invalidSignature
+	}
+
+	now := time.Now
+	if v.now != nil {
+		now = v.now
+	}
+	if time.Unix(claims.ExpiresAt, 0).Add(v.Leeway).Before(now()) {
+		return nil, expiredToken
+	}
+
+	switch claims.QueryHash {
+	case "":
+		return nil, missingQueryHash
+	case contextQueryHash:
+		if !v.AllowContextQsh {
+			return nil, missingQueryHash
+		}
+	default:
+		if claims.QueryHash != queryHash(r, v.BasePath) {
+			return nil, invalidQueryHash
+		}
+	}
+
+	return &claims, nil
+}
+
+// requestToken returns the JWT r is signed with, if any.
+func requestToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "JWT ") {
+		return strings.TrimSpace(auth[len("JWT "):])
+	}
+
+	q := r.URL.Query()
+	if token := q.Get("signed_request"); token != "" {
+		return token
+	}
+
+	return q.Get("jwt")
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(seg, "="))
+	if err != nil {
+		return malformedToken
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return malformedToken
+	}
+
+	return nil
+}
+
+func sign(signingInput string, secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signingInput))
+	return mac.Sum(nil)
+}
+
+// queryHash computes the qsh claim of r: the SHA-256 hash of its method, path
+// relative to basePath and sorted query parameters.
+func queryHash(r *http.Request, basePath string) string {
+	path := strings.TrimPrefix(r.URL.Path, strings.TrimSuffix(basePath, "/"))
+	path = strings.TrimSuffix(path, "/")
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	path = strings.Replace(path, "&", "%26", -1)
+
+	q := r.URL.Query()
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		// The token can't be part of the hash it carries
+		if k == "jwt" || k == "signed_request" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	params := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values := make([]string, len(q[k]))
+		for i, value := range q[k] {
+			values[i] = percentEncode(value)
+		}
+		sort.Strings(values)
+		params = append(params, percentEncode(k)+"="+strings.Join(values, ","))
+	}
+
+	canonical := strings.ToUpper(r.Method) + "&" + path + "&" + strings.Join(params, "&")
+	sum := sha256.Sum256([]byte(canonical))
+	return hex.EncodeToString(sum[:])
+}
+
+// percentEncode escapes s as described by RFC 3986.
+func percentEncode(s string) string {
+	s = url.QueryEscape(s)
+	s = strings.Replace(s, "+", "%20", -1)
+	s = strings.Replace(s, "*", "%2A", -1)
+	return strings.Replace(s, "%7E", "~", -1)
+}
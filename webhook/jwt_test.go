@@ -0,0 +1,191 @@
+package webhook
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type WebhookJWTTestSuite struct {
+	suite.Suite
+	verifier *Verifier
+	now      time.Time
+}
+
+func (suite *WebhookJWTTestSuite) SetupTest() {
+	suite.now = time.Unix(1508230000, 0)
+	suite.verifier = NewVerifier(SecretStoreFunc(func(ctx context.Context, oauthId string) (string, error) {
+		if oauthId != "oauth-1" {
+			return "", errors.New("not installed")
+		}
+		return "s3cr3t", nil
+	}))
+	suite.verifier.now = func() time.Time { return suite.now }
+}
+
+func TestWebhookJWTTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookJWTTestSuite))
+}
+
+func encodeToken(header interface{}, claims interface{}, secret string) string {
+	h, _ := json.Marshal(header)
+	c, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	return input + "." + base64.RawURLEncoding.EncodeToString(sign(input, secret))
+}
+
+func (suite *WebhookJWTTestSuite) token(claims *Claims) string {
+	return encodeToken(jwtHeader{Algorithm: jwtAlgorithm, Type: "JWT"}, claims, "s3cr3t")
+}
+
+// signedToken returns a token bound to r.
+func (suite *WebhookJWTTestSuite) signedToken(r *http.Request, claims *Claims) string {
+	claims.QueryHash = queryHash(r, suite.verifier.BasePath)
+	return suite.token(claims)
+}
+
+func (suite *WebhookJWTTestSuite) claims() *Claims {
+	return &Claims{
+		Issuer:    "oauth-1",
+		Subject:   "42",
+		IssuedAt:  suite.now.Unix(),
+		ExpiresAt: suite.now.Add(time.Hour).Unix(),
+		Context:   &ClaimsContext{RoomId: 2, UserTz: "Europe/London"},
+	}
+}
+
+func (suite *WebhookJWTTestSuite) TestVerifier_Middleware() {
+	assert := assert.New(suite.T())
+
+	var claims *Claims
+	h := suite.verifier.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _ = ClaimsFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader("{}"))
+	want := suite.claims()
+	r.Header.Set("Authorization", "JWT "+suite.signedToken(r, want))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	assert.Equal(http.StatusNoContent, w.Code)
+	assert.Equal(want, claims)
+
+	// Forged requests never reach the handler
+	claims = nil
+	r = httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader("{}"))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	body, _ := ioutil.ReadAll(w.Body)
+	assert.Equal(http.StatusUnauthorized, w.Code)
+	assert.Equal("JWT", w.Header().Get("WWW-Authenticate"))
+	assert.Contains(string(body), missingToken.Error())
+	assert.Nil(claims)
+}
+
+func (suite *WebhookJWTTestSuite) TestVerifier_QueryParameters() {
+	assert := assert.New(suite.T())
+
+	for _, param := range []string{"signed_request", "jwt"} {
+		token := suite.signedToken(httptest.NewRequest(http.MethodGet, "/configure", nil), suite.claims())
+		r := httptest.NewRequest(http.MethodGet, "/configure?"+param+"="+token, nil)
+		claims, err := suite.verifier.Verify(r)
+		assert.Nil(err)
+		assert.Equal("oauth-1", claims.Issuer)
+	}
+}
+
+func (suite *WebhookJWTTestSuite) TestVerifier_QueryHash() {
+	assert := assert.New(suite.T())
+	suite.verifier.BasePath = "/addon/"
+
+	r := httptest.NewRequest(http.MethodGet, "/addon/glance/?room=Ops&b=2&a=x+y&a=w*", nil)
+	claims := suite.claims()
+	claims.QueryHash = queryHash(r, suite.verifier.BasePath)
+	r.Header.Set("Authorization", "JWT "+suite.token(claims))
+
+	_, err := suite.verifier.Verify(r)
+	assert.Nil(err)
+
+	// Known hash of GET&/glance&a=w%2A,x%20y&b=2&room=Ops
+	assert.Equal("89f56b3f4b61a152a2c90a707cd01e5c5b4e890e566fee3f1a93f0daa7cff9a4", claims.QueryHash)
+
+	// Tokens of pages loaded in HipChat are only accepted when allowed
+	claims.QueryHash = contextQueryHash
+	r.Header.Set("Authorization", "JWT "+suite.token(claims))
+	_, err = suite.verifier.Verify(r)
+	assert.Equal(missingQueryHash, err)
+
+	suite.verifier.AllowContextQsh = true
+	_, err = suite.verifier.Verify(r)
+	assert.Nil(err)
+}
+
+func (suite *WebhookJWTTestSuite) TestVerifier_Errors() {
+	assert := assert.New(suite.T())
+
+	expired := suite.claims()
+	expired.ExpiresAt = suite.now.Add(-time.Minute).Unix()
+
+	unknown := suite.claims()
+	unknown.Issuer = "oauth-2"
+
+	otherRequest := suite.claims()
+	otherRequest.QueryHash = "0000"
+
+	pageLoad := suite.claims()
+	pageLoad.QueryHash = contextQueryHash
+
+	testCases := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"TestMissingToken", "", missingToken},
+		{"TestMalformedToken", "a.b", malformedToken},
+		{"TestInvalidHeader", "e30.e30.", invalidAlgorithm},
+		{"TestNoneAlgorithm", encodeToken(jwtHeader{Algorithm: "none"}, suite.claims(), ""), invalidAlgorithm},
+		{"TestUnknownIssuer", suite.token(unknown), unknownIssuer},
+		{"TestForgedSignature", encodeToken(jwtHeader{Algorithm: jwtAlgorithm}, suite.claims(), "guess"), invalidSignature},
+		{"TestExpired", suite.token(expired), expiredToken},
+		{"TestOtherRequest", suite.token(otherRequest), invalidQueryHash},
+		{"TestMissingQueryHash", suite.token(suite.claims()), missingQueryHash},
+		{"TestContextQueryHash", suite.token(pageLoad), missingQueryHash},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+			if tc.token != "" {
+				r.Header.Set("Authorization", fmt.Sprintf("JWT %v", tc.token))
+			}
+
+			_, err := suite.verifier.Verify(r)
+			assert.Equal(tc.want, err)
+		})
+	}
+}
+
+func (suite *WebhookJWTTestSuite) TestVerifier_Leeway() {
+	assert := assert.New(suite.T())
+	suite.verifier.Leeway = 2 * time.Minute
+
+	claims := suite.claims()
+	claims.ExpiresAt = suite.now.Add(-time.Minute).Unix()
+
+	r := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+	r.Header.Set("Authorization", "JWT "+suite.signedToken(r, claims))
+	_, err := suite.verifier.Verify(r)
+	assert.Nil(err)
+}
@@ -0,0 +1,206 @@
+// Package webhook receives the callbacks HipChat sends to room webhooks.
+//
+// A Handler decodes the callbacks into typed events and dispatches them to the
+// callbacks registered for them:
+//
+//	h := webhook.NewHandler()
+//	h.OnRoomMessage(func(ctx context.Context, e *webhook.RoomMessageEvent) error {
+//		log.Printf("%v said %v", e.Message.From.Name, e.Message.Message)
+//		return nil
+//	})
+//	http.Handle("/hipchat/webhook", h)
+//
+// Callbacks of webhooks using JWT authentication are verified by wrapping the
+// handler with a Verifier, which rejects forged requests:
+//
+//	v := webhook.NewVerifier(secrets)
+//	http.Handle("/hipchat/webhook", v.Middleware(h))
+//
+// Webhooks are registered with hipchat.RoomsService.CreateRoomWebhook.
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/theodesp/go-hipchat/hipchat"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// The largest callback payload a Handler reads.
+const maxPayloadSize = 1 << 20
+
+var missingItem = errors.New("missing_item: the payload has no item")
+
+// payload is the envelope of every webhook callback.
+type payload struct {
+	Event
+	Item json.RawMessage `json:"item"`
+}
+
+// payloadError reports an item that can't be decoded into its event type.
+type payloadError struct {
+	err error
+}
+
+func (e *payloadError) Error() string {
+	return "invalid_payload: " + e.err.Error()
+}
+
+type callback func(ctx context.Context, e Event, item json.RawMessage) error
+
+// Handler is an http.Handler receiving HipChat webhook callbacks.
+//
+// It responds with 204 No Content once the callback registered for the event
+// returns, or right away if there is none. Malformed payloads are rejected with
+// 400 Bad Request, and callbacks returning an error result in a 500 Internal
+// Server Error.
+type Handler struct {
+	mu        sync.RWMutex
+	callbacks map[string]callback
+}
+
+// NewHandler returns a Handler with no callbacks registered.
+func NewHandler() *Handler {
+	return &Handler{callbacks: make(map[string]callback)}
+}
+
+// OnRoomMessage registers the callback for room_message events. Webhooks
+// registered for the pattern event are dispatched to it as well.
+func (h *Handler) OnRoomMessage(fn func(ctx context.Context, e *RoomMessageEvent) error) {
+	h.handle(hipchat.WebhookEventRoomMessage, func(ctx context.Context, e Event, item json.RawMessage) error {
+		v := &RoomMessageEvent{Event: e}
+		if err := decodeItem(item, v); err != nil {
+			return err
+		}
+		return fn(ctx, v)
+	})
+}
+
+// OnRoomNotification registers the callback for room_notification events.
+func (h *Handler) OnRoomNotification(fn func(ctx context.Context, e *RoomNotificationEvent) error) {
+	h.handle(hipchat.WebhookEventRoomNotification, func(ctx context.Context, e Event, item json.RawMessage) error {
+		v := &RoomNotificationEvent{Event: e}
+		if err := decodeItem(item, v); err != nil {
+			return err
+		}
+		return fn(ctx, v)
+	})
+}
+
+// OnRoomEnter registers the callback for room_enter events.
+func (h *Handler) OnRoomEnter(fn func(ctx context.Context, e *RoomEnterEvent) error) {
+	h.handle(hipchat.WebhookEventRoomEnter, func(ctx context.Context, e Event, item json.RawMessage) error {
+		v := &RoomEnterEvent{Event: e}
+		if err := decodeItem(item, v); err != nil {
+			return err
+		}
+		return fn(ctx, v)
+	})
+}
+
+// OnRoomExit registers the callback for room_exit events.
+func (h *Handler) OnRoomExit(fn func(ctx context.Context, e *RoomExitEvent) error) {
+	h.handle(hipchat.WebhookEventRoomExit, func(ctx context.Context, e Event, item json.RawMessage) error {
+		v := &RoomExitEvent{Event: e}
+		if err := decodeItem(item, v); err != nil {
+			return err
+		}
+		return fn(ctx, v)
+	})
+}
+
+// OnRoomTopicChange registers the callback for room_topic_change events.
+func (h *Handler) OnRoomTopicChange(fn func(ctx context.Context, e *RoomTopicChangeEvent) error) {
+	h.handle(hipchat.WebhookEventRoomTopicChange, func(ctx context.Context, e Event, item json.RawMessage) error {
+		v := &RoomTopicChangeEvent{Event: e}
+		if err := decodeItem(item, v); err != nil {
+			return err
+		}
+		return fn(ctx, v)
+	})
+}
+
+// OnRoomArchived registers the callback for room_archived events.
+func (h *Handler) OnRoomArchived(fn func(ctx context.Context, e *RoomArchivedEvent) error) {
+	h.handle(hipchat.WebhookEventRoomArchived, func(ctx context.Context, e Event, item json.RawMessage) error {
+		v := &RoomArchivedEvent{Event: e}
+		if err := decodeItem(item, v); err != nil {
+			return err
+		}
+		return fn(ctx, v)
+	})
+}
+
+// OnRoomDeleted registers the callback for room_deleted events.
+func (h *Handler) OnRoomDeleted(fn func(ctx context.Context, e *RoomDeletedEvent) error) {
+	h.handle(hipchat.WebhookEventRoomDeleted, func(ctx context.Context, e Event, item json.RawMessage) error {
+		v := &RoomDeletedEvent{Event: e}
+		if err := decodeItem(item, v); err != nil {
+			return err
+		}
+		return fn(ctx, v)
+	})
+}
+
+// OnRoomFileUpload registers the callback for room_file_upload events.
+func (h *Handler) OnRoomFileUpload(fn func(ctx context.Context, e *RoomFileUploadEvent) error) {
+	h.handle(hipchat.WebhookEventRoomFileUpload, func(ctx context.Context, e Event, item json.RawMessage) error {
+		v := &RoomFileUploadEvent{Event: e}
+		if err := decodeItem(item, v); err != nil {
+			return err
+		}
+		return fn(ctx, v)
+	})
+}
+
+func (h *Handler) handle(event string, cb callback) {
+	h.mu.Lock()
+	h.callbacks[event] = cb
+	h.mu.Unlock()
+}
+
+// ServeHTTP decodes a webhook callback and dispatches it.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var p payload
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxPayloadSize)).Decode(&p); err != nil {
+		http.Error(w, (&payloadError{err}).Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.mu.RLock()
+	cb, ok := h.callbacks[p.Event.Event]
+	h.mu.RUnlock()
+
+	if ok {
+		if err := cb(r.Context(), p.Event, p.Item); err != nil {
+			if _, ok := err.(*payloadError); ok {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeItem(item json.RawMessage, v interface{}) error {
+	if len(item) == 0 {
+		return &payloadError{missingItem}
+	}
+	if err := json.Unmarshal(item, v); err != nil {
+		return &payloadError{err}
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/theodesp/go-hipchat/hipchat"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type WebhookHandlerTestSuite struct {
+	suite.Suite
+	handler *Handler
+	server  *httptest.Server
+}
+
+func (suite *WebhookHandlerTestSuite) SetupTest() {
+	suite.handler = NewHandler()
+	suite.server = httptest.NewServer(suite.handler)
+}
+
+func (suite *WebhookHandlerTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func TestWebhookHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookHandlerTestSuite))
+}
+
+func (suite *WebhookHandlerTestSuite) post(body string) *http.Response {
+	resp, err := http.Post(suite.server.URL, "application/json", strings.NewReader(body))
+	if err != nil {
+		suite.FailNow(err.Error())
+	}
+	resp.Body.Close()
+
+	return resp
+}
+
+func (suite *WebhookHandlerTestSuite) TestHandler_RoomMessage() {
+	assert := assert.New(suite.T())
+
+	var event *RoomMessageEvent
+	suite.handler.OnRoomMessage(func(ctx context.Context, e *RoomMessageEvent) error {
+		event = e
+		return nil
+	})
+
+	resp := suite.post(`{
+		"event": "room_message",
+		"item": {
+			"message": {
+				"date": "2017-10-17T10:52:21.534981+00:00",
+				"from": {"id": 1, "mention_name": "Theo", "name": "Theo Despoudis"},
+				"id": "b1f4e7c2",
+				"mentions": [],
+				"message": "/deploy api",
+				"type": "message"
+			},
+			"room": {"id": 2, "name": "Ops", "links": {"self": "https://api.hipchat.com/v2/room/2"}}
+		},
+		"oauth_client_id": "abc",
+		"webhook_id": 7
+	}`)
+	assert.Equal(http.StatusNoContent, resp.StatusCode)
+
+	assert.Equal(hipchat.WebhookEventRoomMessage, event.Event.Event)
+	assert.Equal("abc", event.OAuthClientId)
+	assert.Equal(int64(7), event.WebhookId)
+	assert.Equal("b1f4e7c2", event.Message.Id)
+	assert.Equal("/deploy api", event.Message.Message)
+	assert.Equal("Theo", event.Message.From.MentionName)
+	assert.Equal(int64(2), event.Room.Id)
+	assert.Equal("Ops", event.Room.Name)
+}
+
+func (suite *WebhookHandlerTestSuite) TestHandler_RoomNotification() {
+	assert := assert.New(suite.T())
+
+	var event *RoomNotificationEvent
+	suite.handler.OnRoomNotification(func(ctx context.Context, e *RoomNotificationEvent) error {
+		event = e
+		return nil
+	})
+
+	resp := suite.post(`{"event": "room_notification", "item": {"message": {"from": "CI", "message": "Build passed",
+		"message_format": "text", "color": "green", "type": "notification"}, "room": {"id": 2}}}`)
+	assert.Equal(http.StatusNoContent, resp.StatusCode)
+
+	assert.Equal("CI", event.Message.FromName)
+	assert.Nil(event.Message.From)
+	assert.Equal("Build passed", event.Message.Message)
+}
+
+func (suite *WebhookHandlerTestSuite) TestHandler_SenderEvents() {
+	assert := assert.New(suite.T())
+
+	var events []string
+	var senders []string
+	suite.handler.OnRoomEnter(func(ctx context.Context, e *RoomEnterEvent) error {
+		events, senders = append(events, e.Event.Event), append(senders, e.Sender.Name)
+		return nil
+	})
+	suite.handler.OnRoomExit(func(ctx context.Context, e *RoomExitEvent) error {
+		events, senders = append(events, e.Event.Event), append(senders, e.Sender.Name)
+		return nil
+	})
+	suite.handler.OnRoomTopicChange(func(ctx context.Context, e *RoomTopicChangeEvent) error {
+		assert.Equal("Release day", e.Topic)
+		events, senders = append(events, e.Event.Event), append(senders, e.Sender.Name)
+		return nil
+	})
+	suite.handler.OnRoomArchived(func(ctx context.Context, e *RoomArchivedEvent) error {
+		events, senders = append(events, e.Event.Event), append(senders, e.Sender.Name)
+		return nil
+	})
+	suite.handler.OnRoomDeleted(func(ctx context.Context, e *RoomDeletedEvent) error {
+		events, senders = append(events, e.Event.Event), append(senders, e.Sender.Name)
+		return nil
+	})
+	suite.handler.OnRoomFileUpload(func(ctx context.Context, e *RoomFileUploadEvent) error {
+		assert.Equal("report.pdf", e.File.Name)
+		assert.Equal(int64(1024), e.File.Size)
+		events, senders = append(events, e.Event.Event), append(senders, e.Sender.Name)
+		return nil
+	})
+
+	item := `"room": {"id": 2, "name": "Ops"}, "sender": {"id": 1, "name": "Theo"}`
+	payloads := []string{
+		`{"event": "room_enter", "item": {` + item + `}}`,
+		`{"event": "room_exit", "item": {` + item + `}}`,
+		`{"event": "room_topic_change", "item": {` + item + `, "topic": "Release day"}}`,
+		`{"event": "room_archived", "item": {` + item + `}}`,
+		`{"event": "room_deleted", "item": {` + item + `}}`,
+		`{"event": "room_file_upload", "item": {` + item + `, "file": {"name": "report.pdf", "size": 1024, "url": "https://f.co/r.pdf"}}}`,
+	}
+	for _, p := range payloads {
+		assert.Equal(http.StatusNoContent, suite.post(p).StatusCode)
+	}
+
+	assert.Equal([]string{
+		hipchat.WebhookEventRoomEnter,
+		hipchat.WebhookEventRoomExit,
+		hipchat.WebhookEventRoomTopicChange,
+		hipchat.WebhookEventRoomArchived,
+		hipchat.WebhookEventRoomDeleted,
+		hipchat.WebhookEventRoomFileUpload,
+	}, events)
+	assert.Equal([]string{"Theo", "Theo", "Theo", "Theo", "Theo", "Theo"}, senders)
+}
+
+func (suite *WebhookHandlerTestSuite) TestHandler_Errors() {
+	assert := assert.New(suite.T())
+
+	suite.handler.OnRoomEnter(func(ctx context.Context, e *RoomEnterEvent) error {
+		return errors.New("boom")
+	})
+
+	testCases := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"TestUnregisteredEvent", `{"event": "room_exit", "item": {}}`, http.StatusNoContent},
+		{"TestInvalidJSON", `{"event": `, http.StatusBadRequest},
+		{"TestMissingItem", `{"event": "room_enter"}`, http.StatusBadRequest},
+		{"TestInvalidItem", `{"event": "room_enter", "item": {"room": "Ops"}}`, http.StatusBadRequest},
+		{"TestCallbackError", `{"event": "room_enter", "item": {"room": {"id": 2}}}`, http.StatusInternalServerError},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			assert.Equal(tc.want, suite.post(tc.body).StatusCode)
+		})
+	}
+
+	resp, err := http.Get(suite.server.URL)
+	assert.Nil(err)
+	assert.Equal(http.StatusMethodNotAllowed, resp.StatusCode)
+	assert.Equal(http.MethodPost, resp.Header.Get("Allow"))
+}